@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"crypto"
 	"crypto/tls"
 	"crypto/x509"
@@ -10,11 +11,16 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/go-kit/log/level"
 	exporter "github.com/greenpau/gobgp_exporter/pkg/gobgp_exporter"
 	"github.com/prometheus/common/promlog"
+	"github.com/prometheus/exporter-toolkit/web"
 )
 
 func loadCertificatePEM(filePath string) (*x509.Certificate, error) {
@@ -90,6 +96,53 @@ func loadKeyPEM(filePath string) (crypto.PrivateKey, error) {
 	return nil, errors.New("no private key PEM block found")
 }
 
+// authTokenEnv is the environment variable with comma-separated tokens for
+// accessing the exporter itself.
+const authTokenEnv = "GOBGP_EXPORTER_AUTH_TOKEN"
+
+// configureAuthentication adds the tokens and the users of basic
+// authentication to the exporter, unless the authentication is disabled.
+func configureAuthentication(e *exporter.Exporter, token, tokenFile, usersFile string, disabled bool) error {
+	if disabled || token == "anonymous" {
+		e.DisableAuthentication()
+		return nil
+	}
+	tokens := splitList(os.Getenv(authTokenEnv))
+	if token != "" {
+		tokens = append(tokens, token)
+	}
+	for _, t := range tokens {
+		if err := e.AddAuthenticationToken(t); err != nil {
+			return err
+		}
+	}
+	if tokenFile != "" {
+		if err := e.LoadAuthenticationTokens(tokenFile); err != nil {
+			return err
+		}
+	}
+	if usersFile != "" {
+		if err := e.LoadAuthenticationUsers(usersFile); err != nil {
+			return err
+		}
+	}
+	if !e.HasAuthentication() {
+		return errors.New("no tokens or users configured, use -auth.disabled to allow unauthenticated access")
+	}
+	return nil
+}
+
+// splitList returns the non-empty elements of a comma-separated list.
+func splitList(s string) []string {
+	l := []string{}
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			l = append(l, e)
+		}
+	}
+	return l
+}
+
 func main() {
 	var listenAddress string
 	var metricsPath string
@@ -105,9 +158,40 @@ func main() {
 	var isShowVersion bool
 	var logLevel string
 	var authToken string
+	var authTokenFile string
+	var authUsersFile string
+	var authDisabled bool
+	var webConfigFile string
+	var exporterMetricsPath string
+	var ribTables string
+	var ribFamilies string
+	var ribTopN int
+	var ribCommunities string
+	var ribWatchPrefixes string
+	var ownedPrefixes string
+	var forbiddenAsns string
+	var bogonFile string
+	var evpnLabels string
+	var vpnRouteDistinguishers string
+	var vpnRouteTargets string
+	var webReadTimeout time.Duration
+	var webWriteTimeout time.Duration
+	var webIdleTimeout time.Duration
+	var webShutdownTimeout time.Duration
+	var lgEnabled bool
+	var lgRateLimit float64
+	var lgBurst int
+	collectors := make(map[string]*bool)
+	noCollectors := make(map[string]*bool)
 
 	flag.StringVar(&listenAddress, "web.listen-address", ":9474", "Address to listen on for web interface and telemetry.")
 	flag.StringVar(&metricsPath, "web.telemetry-path", "/metrics", "Path under which to expose metrics.")
+	flag.StringVar(&exporterMetricsPath, "web.exporter-telemetry-path", "/exporter-metrics", "Path under which to expose the metrics of the exporter itself.")
+	flag.StringVar(&webConfigFile, "web.config.file", "", "Optional path to configuration file that can enable TLS, client certificate verification, or basic authentication.")
+	flag.DurationVar(&webReadTimeout, "web.read-timeout", 30*time.Second, "Maximum duration for reading an HTTP request, including its headers.")
+	flag.DurationVar(&webWriteTimeout, "web.write-timeout", 2*time.Minute, "Maximum duration for writing an HTTP response. It must be longer than the slowest scrape, e.g. of large route table walks.")
+	flag.DurationVar(&webIdleTimeout, "web.idle-timeout", 2*time.Minute, "Maximum duration an idle keep-alive HTTP connection is kept open.")
+	flag.DurationVar(&webShutdownTimeout, "web.shutdown-timeout", 30*time.Second, "Maximum duration for draining in-flight HTTP requests on SIGTERM or SIGINT before the remaining connections are closed.")
 	flag.StringVar(&serverAddress, "gobgp.address", "127.0.0.1:50051", "gRPC API address of GoBGP server.")
 	flag.BoolVar(&serverTLS, "gobgp.tls", false, "Whether to enable TLS for gRPC API access.")
 	flag.StringVar(&serverTLSCAPath, "gobgp.tls-ca", "", "Optional path to PEM file with CA certificates to be trusted for gRPC API access.")
@@ -116,7 +200,28 @@ func main() {
 	flag.StringVar(&serverTLSClientKeyPath, "gobgp.tls-client-key", "", "Optional path to PEM file with client key to be used for client authentication.")
 	flag.IntVar(&pollTimeout, "gobgp.timeout", 2, "Timeout on gRPC requests to a GoBGP server.")
 	flag.IntVar(&pollInterval, "gobgp.poll-interval", 15, "The minimum interval (in seconds) between collections from a GoBGP server.")
-	flag.StringVar(&authToken, "auth.token", "anonymous", "The X-Token for accessing the exporter itself")
+	flag.StringVar(&ribTables, "rib.tables", "global", "Comma-separated list of route tables (global, local, adj_in, adj_out) walked by the route table collectors.")
+	flag.StringVar(&ribFamilies, "rib.families", "ipv4,ipv6", "Comma-separated list of address families walked by the route table collectors.")
+	flag.IntVar(&ribTopN, "rib.top-n", 10, "The number of top entries, e.g. origin AS numbers, exported by the route table collectors.")
+	flag.StringVar(&ribCommunities, "rib.communities", "", "Comma-separated watch list of communities, e.g. 65000:666,no-export,65000:1:2,rt:65000:100.")
+	flag.StringVar(&ribWatchPrefixes, "rib.watch-prefixes", "", "Comma-separated watch list of prefixes looked up in the global route table.")
+	flag.StringVar(&ownedPrefixes, "hijack.owned-prefixes", "", "Comma-separated list of owned prefixes with allowed origin AS numbers, e.g. 192.0.2.0/24=65000|65001.")
+	flag.StringVar(&forbiddenAsns, "hijack.forbidden-asns", "", "Comma-separated list of AS numbers not expected in the AS path of owned prefixes.")
+	flag.StringVar(&bogonFile, "bogon.file", "", "Optional path to the file overriding the built-in list of bogon prefixes and AS numbers.")
+	flag.StringVar(&evpnLabels, "evpn.labels", "", "Comma-separated list of optional labels (rd, vni) of EVPN route counts, limited to the top entries of each route type.")
+	flag.StringVar(&vpnRouteDistinguishers, "vpn.route-distinguishers", "", "Comma-separated allowlist of route distinguishers of L3VPN route counts, the top entries are exported when empty.")
+	flag.StringVar(&vpnRouteTargets, "vpn.route-targets", "", "Comma-separated allowlist of route targets of L3VPN route counts, the top entries are exported when empty.")
+	for _, name := range exporter.GetCollectors() {
+		collectors[name] = flag.Bool("collector."+name, exporter.IsCollectorEnabledByDefault(name), exporter.GetCollectorHelp(name))
+		noCollectors[name] = flag.Bool("no-collector."+name, false, "Disable the "+name+" collector.")
+	}
+	flag.StringVar(&authToken, "auth.token", "", "The token for accessing the exporter itself. Prefer auth.token-file or "+authTokenEnv+" environment variable, because the command line is visible to other users.")
+	flag.StringVar(&authTokenFile, "auth.token-file", "", "Path to the file with the tokens for accessing the exporter itself, one per line.")
+	flag.StringVar(&authUsersFile, "auth.basic-users-file", "", "Path to the file with the users of HTTP basic authentication in htpasswd format with bcrypt hashed passwords.")
+	flag.BoolVar(&authDisabled, "auth.disabled", false, "Allow unauthenticated access to the exporter itself.")
+	flag.BoolVar(&lgEnabled, "lg.enabled", false, "Enable the looking glass at /lg/route and /lg/neighbors.")
+	flag.Float64Var(&lgRateLimit, "lg.rate-limit", 1, "The number of looking glass requests per second allowed on per client basis, unlimited when 0.")
+	flag.IntVar(&lgBurst, "lg.burst", 5, "The number of looking glass requests allowed in a burst on per client basis.")
 	flag.BoolVar(&isShowMetrics, "metrics", false, "Display available metrics")
 	flag.BoolVar(&isShowVersion, "version", false, "version information")
 	flag.StringVar(&logLevel, "log.level", "info", "logging severity level")
@@ -131,8 +236,28 @@ func main() {
 	flag.Parse()
 
 	opts := exporter.Options{
-		Address: serverAddress,
-		Timeout: pollTimeout,
+		Address:   serverAddress,
+		Timeout:   pollTimeout,
+		RibTopN:   ribTopN,
+		BogonFile: bogonFile,
+	}
+
+	opts.RibTables = splitList(ribTables)
+	opts.RibFamilies = splitList(ribFamilies)
+	opts.RibCommunities = splitList(ribCommunities)
+	opts.RibWatchPrefixes = splitList(ribWatchPrefixes)
+	opts.OwnedPrefixes = splitList(ownedPrefixes)
+	opts.ForbiddenAsns = splitList(forbiddenAsns)
+	opts.EvpnLabels = splitList(evpnLabels)
+	opts.VpnRouteDistinguishers = splitList(vpnRouteDistinguishers)
+	opts.VpnRouteTargets = splitList(vpnRouteTargets)
+	opts.LookingGlassRateLimit = lgRateLimit
+	opts.LookingGlassBurst = lgBurst
+	opts.Collectors = []string{}
+	for _, name := range exporter.GetCollectors() {
+		if *collectors[name] && !*noCollectors[name] {
+			opts.Collectors = append(opts.Collectors, name)
+		}
 	}
 
 	allowedLogLevel := &promlog.AllowedLevel{}
@@ -223,34 +348,144 @@ func main() {
 	}
 
 	e.SetPollInterval(int64(pollInterval))
-	if err := e.AddAuthenticationToken(authToken); err != nil {
+	if err := configureAuthentication(e, authToken, authTokenFile, authUsersFile, authDisabled); err != nil {
 		level.Error(logger).Log(
-			"msg", "failed to add authentication token",
+			"msg", "failed to configure authentication",
 			"error", err.Error(),
 		)
 		os.Exit(1)
 	}
+	if authToken == "anonymous" {
+		level.Warn(logger).Log(
+			"msg", "the anonymous token is deprecated, use -auth.disabled instead",
+		)
+	}
 
 	level.Info(logger).Log(
 		"msg", "exporter configuration",
 		"min_scrape_interval", e.GetPollInterval(),
 	)
 
-	http.HandleFunc(metricsPath, func(w http.ResponseWriter, r *http.Request) {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc(metricsPath, func(w http.ResponseWriter, r *http.Request) {
 		e.Scrape(w, r)
 	})
 
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc(exporterMetricsPath, func(w http.ResponseWriter, r *http.Request) {
+		e.ScrapeExporter(w, r)
+	})
+
+	mux.HandleFunc("/hijacks", func(w http.ResponseWriter, r *http.Request) {
+		e.Hijacks(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/peers", func(w http.ResponseWriter, r *http.Request) {
+		e.APIPeers(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/router", func(w http.ResponseWriter, r *http.Request) {
+		e.APIRouter(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/rib", func(w http.ResponseWriter, r *http.Request) {
+		e.APIRib(w, r)
+	})
+
+	if lgEnabled {
+		mux.HandleFunc("/lg/route", func(w http.ResponseWriter, r *http.Request) {
+			e.LookingGlassRoute(w, r)
+		})
+
+		mux.HandleFunc("/lg/neighbors", func(w http.ResponseWriter, r *http.Request) {
+			e.LookingGlassNeighbors(w, r)
+		})
+	}
+
+	mux.HandleFunc("/-/healthy", func(w http.ResponseWriter, r *http.Request) {
+		e.Healthy(w, r)
+	})
+
+	mux.HandleFunc("/-/ready", func(w http.ResponseWriter, r *http.Request) {
+		e.Ready(w, r)
+	})
+
+	mux.Handle(exporter.AssetsPath+"/", e.Assets())
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
 		e.Summary(metricsPath, w, r)
 	})
 
-	level.Info(logger).Log("listen_on ", listenAddress)
+	if err := web.Validate(webConfigFile); err != nil {
+		level.Error(logger).Log(
+			"msg", "invalid web configuration file",
+			"error", err.Error(),
+		)
+		os.Exit(1)
+	}
 
-	if err := http.ListenAndServe(listenAddress, nil); err != nil {
+	// The web configuration file is read on each new TLS connection, so
+	// that the renewed certificates are used without a restart.
+	webSystemdSocket := false
+	webFlags := &web.FlagConfig{
+		WebListenAddresses: &[]string{listenAddress},
+		WebSystemdSocket:   &webSystemdSocket,
+		WebConfigFile:      &webConfigFile,
+	}
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: webReadTimeout,
+		ReadTimeout:       webReadTimeout,
+		WriteTimeout:      webWriteTimeout,
+		IdleTimeout:       webIdleTimeout,
+	}
+	serverErrors := make(chan error, 1)
+	go func() {
+		serverErrors <- web.ListenAndServe(server, webFlags, logger)
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	select {
+	case err := <-serverErrors:
 		level.Error(logger).Log(
 			"msg", "listener failed",
 			"error", err.Error(),
 		)
+		e.Close() //nolint:errcheck
 		os.Exit(1)
+	case sig := <-signals:
+		level.Info(logger).Log(
+			"msg", "shutting down",
+			"signal", sig.String(),
+			"timeout", webShutdownTimeout,
+		)
+	}
+
+	// The in-flight requests are drained until the deadline. Then the
+	// in-flight queries to GoBGP are cancelled, and the remaining
+	// connections are closed.
+	ctx, cancel := context.WithTimeout(context.Background(), webShutdownTimeout)
+	defer cancel()
+	drainErr := server.Shutdown(ctx)
+	if err := e.Close(); err != nil {
+		level.Warn(logger).Log(
+			"msg", "failed closing GoBGP connection",
+			"error", err.Error(),
+		)
 	}
+	if drainErr != nil {
+		level.Warn(logger).Log(
+			"msg", "in-flight requests were not drained before the shutdown deadline",
+			"error", drainErr.Error(),
+		)
+		server.Close() //nolint:errcheck
+	}
+	level.Info(logger).Log(
+		"msg", "exporter stopped",
+	)
 }
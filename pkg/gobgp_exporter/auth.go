@@ -15,59 +15,291 @@
 package exporter
 
 import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/subtle"
 	"fmt"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/go-kit/log/level"
+	"golang.org/x/crypto/bcrypt"
 )
 
+// The endpoints the access to is granted by tokens.
+const (
+	EndpointMetrics = "metrics"
+	EndpointSummary = "summary"
+	EndpointHijacks = "hijacks"
+	EndpointAPI     = "api"
+	EndpointLG      = "lg"
+)
+
+var endpoints = []string{EndpointMetrics, EndpointSummary, EndpointHijacks, EndpointAPI, EndpointLG}
+
+// The reasons of authorization failures.
+const (
+	authFailureMissing   = "missing"
+	authFailureInvalid   = "invalid"
+	authFailureExpired   = "expired"
+	authFailureForbidden = "forbidden"
+)
+
+var authFailureReasons = []string{authFailureMissing, authFailureInvalid, authFailureExpired, authFailureForbidden}
+
+// Token is an access token along with its permissions. The token with no
+// endpoints or no targets is allowed to access all of them. The token with
+// zero expiry time never expires.
+type Token struct {
+	Endpoints []string
+	Targets   []string
+	Expires   time.Time
+}
+
+// allows returns true when the token is allowed to access the endpoint of
+// the target.
+func (t *Token) allows(endpoint, target string) bool {
+	if len(t.Endpoints) > 0 && !containsString(t.Endpoints, endpoint) {
+		return false
+	}
+	if len(t.Targets) > 0 && !containsString(t.Targets, target) {
+		return false
+	}
+	return true
+}
+
+// parseToken parses a token along with its optional permissions, e.g.
+// "s3cr3t endpoints=metrics,api targets=127.0.0.1:50051
+// expires=2030-01-01T00:00:00Z".
+func parseToken(s string) (string, *Token, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return "", nil, fmt.Errorf("invalid empty token")
+	}
+	t := &Token{}
+	for _, field := range fields[1:] {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			return "", nil, fmt.Errorf("invalid token permission %q", field)
+		}
+		switch parts[0] {
+		case "endpoints":
+			for _, endpoint := range strings.Split(parts[1], ",") {
+				if !containsString(endpoints, endpoint) {
+					return "", nil, fmt.Errorf("unsupported token endpoint %q", endpoint)
+				}
+				t.Endpoints = append(t.Endpoints, endpoint)
+			}
+		case "targets":
+			t.Targets = strings.Split(parts[1], ",")
+		case "expires":
+			expires, err := time.Parse(time.RFC3339, parts[1])
+			if err != nil {
+				return "", nil, fmt.Errorf("invalid token expiry %q: %s", parts[1], err)
+			}
+			t.Expires = expires
+		default:
+			return "", nil, fmt.Errorf("unsupported token permission %q", parts[0])
+		}
+	}
+	return fields[0], t, nil
+}
+
 // AddAuthenticationToken adds an authentication token for accessing
-// the exporter itself.
+// the exporter itself. The token has access to all endpoints and targets.
 func (e *Exporter) AddAuthenticationToken(s string) error {
+	return e.AddScopedAuthenticationToken(s, &Token{})
+}
+
+// AddScopedAuthenticationToken adds an authentication token with the
+// permissions limited to the endpoints and the targets of the token.
+func (e *Exporter) AddScopedAuthenticationToken(s string, t *Token) error {
 	if s == "" {
 		return fmt.Errorf("invalid empty token")
 	}
-	e.Tokens[s] = true
+	for _, endpoint := range t.Endpoints {
+		if !containsString(endpoints, endpoint) {
+			return fmt.Errorf("unsupported token endpoint %q", endpoint)
+		}
+	}
+	e.Tokens[s] = t
 	return nil
 }
 
-func (e *Exporter) authorize(r *http.Request) (string, bool) {
-	if _, exists := e.Tokens["anonymous"]; exists {
-		return "anonymous", true
-	}
-	var invalidToken bool
-	tokens := []string{"x_token", "x-token", "X-Token"}
-	for _, t := range tokens {
-		token := r.Header.Get(t)
-		if token != "" {
-			if _, exists := e.Tokens[token]; exists {
-				return token, true
-			}
-			invalidToken = true
+// AddAuthenticationUser adds a user of HTTP basic authentication for
+// accessing the exporter itself. The password must be hashed with bcrypt.
+func (e *Exporter) AddAuthenticationUser(name, hash string) error {
+	if name == "" {
+		return fmt.Errorf("invalid empty user name")
+	}
+	if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+		return fmt.Errorf("invalid bcrypt password hash of user %q: %s", name, err)
+	}
+	e.Users[name] = []byte(hash)
+	return nil
+}
+
+// readAuthenticationFile returns the non-empty lines of a file, except
+// the comments.
+func readAuthenticationFile(fp string) ([]string, error) {
+	f, err := os.Open(filepath.Clean(fp))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	lines := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
 		}
+		lines = append(lines, line)
 	}
+	return lines, scanner.Err()
+}
 
-	for _, t := range tokens {
-		token := r.URL.Query().Get(t)
-		if token != "" {
-			if _, exists := e.Tokens[token]; exists {
-				return token, true
-			}
-			invalidToken = true
+// LoadAuthenticationTokens adds the authentication tokens from a file with
+// one token per line. Each token is optionally followed by its
+// permissions, see parseToken.
+func (e *Exporter) LoadAuthenticationTokens(fp string) error {
+	lines, err := readAuthenticationFile(fp)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		s, t, err := parseToken(line)
+		if err != nil {
+			return err
+		}
+		if err := e.AddScopedAuthenticationToken(s, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadAuthenticationUsers adds the users of HTTP basic authentication from
+// a file in htpasswd format with bcrypt hashed passwords, i.e. user:hash.
+func (e *Exporter) LoadAuthenticationUsers(fp string) error {
+	lines, err := readAuthenticationFile(fp)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid user entry for %q, expected user:hash", parts[0])
+		}
+		if err := e.AddAuthenticationUser(parts[0], parts[1]); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	if invalidToken {
-		level.Warn(e.logger).Log(
-			"reason", "unauthorized access with invalid token",
-			"remote_address", r.RemoteAddr,
-		)
-	} else {
-		level.Warn(e.logger).Log(
-			"reason", "unauthorized access without auth token",
-			"remote_address", r.RemoteAddr,
-		)
+// DisableAuthentication allows unauthenticated access to the exporter.
+func (e *Exporter) DisableAuthentication() {
+	e.authDisabled = true
+}
+
+// HasAuthentication returns true when the access to the exporter is either
+// explicitly unauthenticated, or there are tokens or users configured.
+func (e *Exporter) HasAuthentication() bool {
+	return e.authDisabled || len(e.Tokens) > 0 || len(e.Users) > 0
+}
+
+// getToken returns the configured token matching the provided one. The
+// SHA-256 hashes of the tokens are compared in constant time, so that the
+// response time reveals neither the content nor the length of the tokens.
+func (e *Exporter) getToken(token string) *Token {
+	var match *Token
+	hash := sha256.Sum256([]byte(token))
+	for s, t := range e.Tokens {
+		h := sha256.Sum256([]byte(s))
+		if subtle.ConstantTimeCompare(h[:], hash[:]) == 1 {
+			match = t
+		}
+	}
+	return match
+}
+
+// dummyPasswordHash is compared with the password of an unknown user, so
+// that the response time does not reveal which users exist.
+var dummyPasswordHash = []byte("$2a$10$Pp1lVUXMJ1IVmwXy9pGcKexUk7TvOosCDwJWm2nuaCdUEsaLn6Joi")
+
+// authenticate authenticates a request with either HTTP basic
+// authentication, a bearer token in Authorization header, or a token in
+// X-Token header. It returns the permissions of the token or the user,
+// and the reason of the failure, if any.
+func (e *Exporter) authenticate(r *http.Request) (*Token, string) {
+	if user, password, ok := r.BasicAuth(); ok {
+		hash, exists := e.Users[user]
+		if !exists {
+			hash = dummyPasswordHash
+		}
+		if bcrypt.CompareHashAndPassword(hash, []byte(password)) == nil && exists {
+			return &Token{}, ""
+		}
+		return nil, authFailureInvalid
+	}
+
+	token := r.Header.Get("X-Token")
+	if s := r.Header.Get("Authorization"); len(s) > 7 && strings.EqualFold(s[:7], "Bearer ") {
+		token = strings.TrimSpace(s[7:])
+	}
+	if token == "" {
+		return nil, authFailureMissing
+	}
+	t := e.getToken(token)
+	if t == nil {
+		return nil, authFailureInvalid
+	}
+	if !t.Expires.IsZero() && time.Now().After(t.Expires) {
+		return nil, authFailureExpired
+	}
+	return t, ""
+}
+
+// authorize checks whether a request is allowed to access an endpoint of
+// the router polled by the exporter. When it is not, it responds with an
+// error and counts the failure.
+func (e *Exporter) authorize(w http.ResponseWriter, r *http.Request, endpoint string) bool {
+	if e.authDisabled {
+		return true
 	}
 
-	return "", false
+	t, reason := e.authenticate(r)
+	if t != nil && !t.allows(endpoint, e.address) {
+		reason = authFailureForbidden
+	}
+	if reason == "" {
+		return true
+	}
+	e.authFailures.WithLabelValues(reason).Inc()
+
+	msg := "unauthorized access with " + reason + " credentials"
+	if reason == authFailureMissing && (r.URL.Query().Get("x-token") != "" || r.URL.Query().Get("x_token") != "") {
+		msg = "unauthorized access with token in query string, use Authorization header instead"
+	}
+	level.Warn(e.logger).Log(
+		"reason", msg,
+		"endpoint", endpoint,
+		"remote_address", r.RemoteAddr,
+	)
+
+	if reason == authFailureForbidden {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return false
+	}
+	if len(e.Users) > 0 {
+		w.Header().Set("WWW-Authenticate", `Basic realm="`+appName+`", charset="UTF-8"`)
+	} else {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="`+appName+`"`)
+	}
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	return false
 }
@@ -0,0 +1,164 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestParseToken(t *testing.T) {
+	cases := []struct {
+		line     string
+		endpoint string
+		target   string
+		allowed  bool
+		ok       bool
+	}{
+		{line: "s3cr3t", endpoint: EndpointHijacks, target: "127.0.0.1:50051", allowed: true, ok: true},
+		{line: "s3cr3t endpoints=metrics,api", endpoint: EndpointAPI, target: "127.0.0.1:50051", allowed: true, ok: true},
+		{line: "s3cr3t endpoints=metrics,api", endpoint: EndpointSummary, target: "127.0.0.1:50051", allowed: false, ok: true},
+		{line: "s3cr3t targets=127.0.0.1:50051", endpoint: EndpointMetrics, target: "127.0.0.1:50051", allowed: true, ok: true},
+		{line: "s3cr3t targets=127.0.0.1:50051", endpoint: EndpointMetrics, target: "192.0.2.1:50051", allowed: false, ok: true},
+		{line: "s3cr3t expires=2030-01-01T00:00:00Z", endpoint: EndpointMetrics, allowed: true, ok: true},
+		{line: "s3cr3t expires=2030-01-01", ok: false},
+		{line: "s3cr3t endpoints=admin", ok: false},
+		{line: "s3cr3t scope=metrics", ok: false},
+	}
+	for _, test := range cases {
+		s, token, err := parseToken(test.line)
+		if test.ok && err != nil {
+			t.Errorf("expected no error w/ %q, but got %q", test.line, err)
+			continue
+		}
+		if !test.ok {
+			if err == nil {
+				t.Errorf("expected error w/ %q, but got none", test.line)
+			}
+			continue
+		}
+		if s != "s3cr3t" {
+			t.Errorf("expected token s3cr3t w/ %q, but got %q", test.line, s)
+		}
+		if allowed := token.allows(test.endpoint, test.target); allowed != test.allowed {
+			t.Errorf("expected access %t to %s of %s w/ %q, but got %t", test.allowed, test.endpoint, test.target, test.line, allowed)
+		}
+	}
+}
+
+func newTestExporter(t *testing.T) *Exporter {
+	e := &Exporter{
+		address: "127.0.0.1:50051",
+		Tokens:  make(map[string]*Token),
+		Users:   make(map[string][]byte),
+		authFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "auth_failure_count",
+		}, []string{"reason"}),
+		logger: log.NewNopLogger(),
+	}
+	tokens := map[string]*Token{
+		"any":     {},
+		"expired": {Expires: time.Now().Add(-time.Hour)},
+		"metrics": {Endpoints: []string{EndpointMetrics}},
+		"remote":  {Targets: []string{"192.0.2.1:50051"}},
+	}
+	for s, token := range tokens {
+		if err := e.AddScopedAuthenticationToken(s, token); err != nil {
+			t.Fatalf("failed adding token %q: %s", s, err)
+		}
+	}
+	if err := e.AddAuthenticationUser("admin", string(dummyPasswordHash)); err != nil {
+		t.Fatalf("failed adding user: %s", err)
+	}
+	return e
+}
+
+func TestAuthorize(t *testing.T) {
+	cases := []struct {
+		name     string
+		token    string
+		user     string
+		password string
+		endpoint string
+		code     int
+		reason   string
+	}{
+		{name: "missing", endpoint: EndpointMetrics, code: http.StatusUnauthorized, reason: authFailureMissing},
+		{name: "invalid", token: "wrong", endpoint: EndpointMetrics, code: http.StatusUnauthorized, reason: authFailureInvalid},
+		{name: "token prefix", token: "an", endpoint: EndpointMetrics, code: http.StatusUnauthorized, reason: authFailureInvalid},
+		{name: "token suffix", token: "anyone", endpoint: EndpointMetrics, code: http.StatusUnauthorized, reason: authFailureInvalid},
+		{name: "expired", token: "expired", endpoint: EndpointMetrics, code: http.StatusUnauthorized, reason: authFailureExpired},
+		{name: "endpoint", token: "metrics", endpoint: EndpointHijacks, code: http.StatusForbidden, reason: authFailureForbidden},
+		{name: "target", token: "remote", endpoint: EndpointMetrics, code: http.StatusForbidden, reason: authFailureForbidden},
+		{name: "unknown user", user: "guest", password: "gobgp_exporter", endpoint: EndpointSummary, code: http.StatusUnauthorized, reason: authFailureInvalid},
+		{name: "wrong password", user: "admin", password: "wrong", endpoint: EndpointSummary, code: http.StatusUnauthorized, reason: authFailureInvalid},
+		{name: "token", token: "any", endpoint: EndpointAPI, code: http.StatusOK},
+		{name: "scoped token", token: "metrics", endpoint: EndpointMetrics, code: http.StatusOK},
+		{name: "user", user: "admin", password: "gobgp_exporter", endpoint: EndpointSummary, code: http.StatusOK},
+	}
+	for _, test := range cases {
+		e := newTestExporter(t)
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if test.token != "" {
+			r.Header.Set("Authorization", "Bearer "+test.token)
+		}
+		if test.user != "" {
+			r.SetBasicAuth(test.user, test.password)
+		}
+		w := httptest.NewRecorder()
+		allowed := e.authorize(w, r, test.endpoint)
+		if allowed != (test.code == http.StatusOK) {
+			t.Errorf("%s: expected access %t, but got %t", test.name, test.code == http.StatusOK, allowed)
+		}
+		if !allowed && w.Code != test.code {
+			t.Errorf("%s: expected status %d, but got %d", test.name, test.code, w.Code)
+		}
+		if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s: expected WWW-Authenticate header, but got none", test.name)
+		}
+		for _, reason := range authFailureReasons {
+			expected := 0.0
+			if reason == test.reason {
+				expected = 1
+			}
+			if count := testutil.ToFloat64(e.authFailures.WithLabelValues(reason)); count != expected {
+				t.Errorf("%s: expected %v %s failures, but got %v", test.name, expected, reason, count)
+			}
+		}
+	}
+}
+
+func TestHijacksScope(t *testing.T) {
+	e := newTestExporter(t)
+	if err := e.AddScopedAuthenticationToken("summary", &Token{Endpoints: []string{EndpointSummary}}); err != nil {
+		t.Fatalf("failed adding token: %s", err)
+	}
+	r := httptest.NewRequest(http.MethodGet, "/hijacks", nil)
+	r.Header.Set("X-Token", "summary")
+	w := httptest.NewRecorder()
+	e.Hijacks(w, r)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected status %d w/ summary token, but got %d", http.StatusForbidden, w.Code)
+	}
+	if count := testutil.ToFloat64(e.authFailures.WithLabelValues(authFailureForbidden)); count != 1 {
+		t.Errorf("expected 1 forbidden failure, but got %v", count)
+	}
+}
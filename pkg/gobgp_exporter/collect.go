@@ -21,46 +21,62 @@ import (
 	"github.com/go-kit/log/level"
 	gobgpapi "github.com/osrg/gobgp/v3/api"
 	"github.com/prometheus/client_golang/prometheus"
-	"golang.org/x/net/context"
 )
 
 // GatherMetrics collect data from a GoBGP router and stores them
 // as Prometheus metrics.
 func (n *RouterNode) GatherMetrics() {
+	n.gatherMetrics(n.collectors)
+}
+
+// gatherMetrics collects the data of the selected collectors. The metrics
+// of each selection are cached separately, so that the scrapes of
+// different selections at different intervals do not interfere.
+func (n *RouterNode) gatherMetrics(collectors []string) {
 	n.Lock()
 	defer n.Unlock()
 
 	level.Debug(n.logger).Log(
 		"msg", "GatherMetrics() locked",
+		"collectors", getCacheKey(collectors),
 	)
 
-	if time.Now().Unix() < n.nextCollectionTicker {
+	if n.caches == nil {
+		n.caches = make(map[string]*metricCache)
+	}
+	cache, exists := n.caches[getCacheKey(collectors)]
+	if !exists {
+		cache = &metricCache{}
+		n.caches[getCacheKey(collectors)] = cache
+	}
+	if time.Now().Unix() < cache.nextCollectionTicker {
 		return
 	}
 	start := time.Now()
-	if len(n.metrics) > 0 {
-		n.metrics = n.metrics[:0]
-		level.Debug(n.logger).Log(
-			"msg", "GatherMetrics() cleared metrics",
-		)
-	}
+	n.metrics = cache.metrics[:0]
+	n.nextCollectionTicker = cache.nextCollectionTicker
 	upValue := 1
 
 	// What is RouterID and AS number of this GoBGP server?
-	server, err := n.client.GetBgp(context.Background(), &gobgpapi.GetBgpRequest{})
+	server, err := n.client.GetBgp(n.ctx, &gobgpapi.GetBgpRequest{})
 	if err != nil {
 		n.IncrementErrorCounter()
+		n.recordError("failed query gobgp server", err)
 		level.Error(n.logger).Log(
 			"msg", "failed query gobgp server",
 			"error", err.Error(),
 		)
+		n.global = nil
 		if IsConnectionError(err) {
 			n.connected = false
+			n.peers = nil
+			n.ribSizes = nil
 			upValue = 0
 		}
 	} else {
 		n.routerID = server.Global.RouterId
 		n.localAS = server.Global.Asn
+		n.global = server.Global
 		level.Debug(n.logger).Log(
 			"msg", "router info",
 			"router_id", n.routerID,
@@ -71,17 +87,29 @@ func (n *RouterNode) GatherMetrics() {
 
 	if n.connected {
 		var wg sync.WaitGroup
-		wg.Add(2)
-		go func() {
-			defer wg.Done()
-			n.GetRibCounters()
-		}()
-		go func() {
-			defer wg.Done()
-			n.GetPeers()
-		}()
+		if containsString(collectors, "rib") {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				n.GetRibCounters()
+			}()
+		}
+		if containsString(collectors, "peers") {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				n.GetPeers()
+			}()
+		}
 		wg.Wait()
 
+		n.GetRibStats(collectors)
+		if len(n.ribWatchPrefixes) > 0 && containsString(collectors, "watch") {
+			n.GetWatchedPrefixes()
+		}
+		if len(n.ownedPrefixes) > 0 && containsString(collectors, "hijack") {
+			n.GetHijacks()
+		}
 	}
 
 	// Generic Metrics
@@ -124,7 +152,13 @@ func (n *RouterNode) GatherMetrics() {
 		))
 	}
 
-	n.nextCollectionTicker = time.Now().Add(time.Duration(n.pollInterval) * time.Second).Unix()
+	// Global BGP configuration
+	if n.global != nil && containsString(collectors, "router") {
+		n.GetRouterInfo()
+	}
+
+	cache.nextCollectionTicker = time.Now().Add(time.Duration(n.pollInterval) * time.Second).Unix()
+	cache.metrics = n.metrics
 
 	if upValue > 0 {
 		n.result = "success"
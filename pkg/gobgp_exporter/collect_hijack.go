@@ -0,0 +1,193 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log/level"
+	gobgpapi "github.com/osrg/gobgp/v3/api"
+	"github.com/osrg/gobgp/v3/pkg/packet/bgp"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The types of violations reported by hijack detection.
+const (
+	hijackMoreSpecific   = "more_specific"
+	hijackOriginMismatch = "origin_mismatch"
+	hijackForbiddenAsn   = "forbidden_asn"
+	// hijackAmbiguousOrigin is a path ending with an AS_SET, the origin
+	// of which cannot be checked against the allowed AS numbers.
+	hijackAmbiguousOrigin = "ambiguous_origin"
+)
+
+var hijackTypes = []string{hijackMoreSpecific, hijackOriginMismatch, hijackForbiddenAsn, hijackAmbiguousOrigin}
+
+// maxHijackViolations is the maximum number of violations kept for the
+// details page.
+const maxHijackViolations = 1000
+
+// ownedPrefix is a prefix owned by the operator of the router, along with
+// the AS numbers allowed to originate it.
+type ownedPrefix struct {
+	prefix     string
+	family     string
+	length     int
+	allowedAsn map[uint32]bool
+}
+
+// HijackViolation is a path to an owned prefix violating hijack detection
+// rules.
+type HijackViolation struct {
+	Type        string `json:"type"`
+	OwnedPrefix string `json:"owned_prefix"`
+	Prefix      string `json:"prefix"`
+	OriginAsn   uint32 `json:"origin_asn"`
+	AsPath      string `json:"as_path"`
+	NextHop     string `json:"next_hop"`
+	Peer        string `json:"peer"`
+	Best        bool   `json:"best"`
+}
+
+// parseOwnedPrefix parses an owned prefix along with the AS numbers allowed
+// to originate it, e.g. 192.0.2.0/24=65000|65001.
+func parseOwnedPrefix(s string) (*ownedPrefix, error) {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, fmt.Errorf("owned prefix %q has no allowed origin AS numbers", s)
+	}
+	prefix, family, err := parsePrefix(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	length, _ := getPrefixLength(prefix)
+	p := &ownedPrefix{
+		prefix:     prefix,
+		family:     family,
+		length:     length,
+		allowedAsn: make(map[uint32]bool),
+	}
+	for _, asn := range strings.Split(parts[1], "|") {
+		i, err := strconv.ParseUint(strings.TrimSpace(asn), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("owned prefix %q has invalid origin AS number %q", s, asn)
+		}
+		p.allowedAsn[uint32(i)] = true
+	}
+	return p, nil
+}
+
+// GetHijacks looks up the owned prefixes and their more specific prefixes
+// in the global route table, and collects the paths with unexpected origin
+// AS numbers or forbidden AS numbers in AS path.
+func (n *RouterNode) GetHijacks() {
+	violations := []*HijackViolation{}
+	for _, owned := range n.ownedPrefixes {
+		counts := make(map[string]uint64)
+		t := &ribTarget{
+			table:     "global",
+			tableType: gobgpapi.TableType_GLOBAL,
+			family:    owned.family,
+		}
+		err := n.walkRib(t, []*gobgpapi.TableLookupPrefix{
+			{
+				Prefix: owned.prefix,
+				Type:   gobgpapi.TableLookupPrefix_LONGER,
+			},
+		}, func(d *ribDestination) {
+			length, _ := getPrefixLength(d.GetPrefix())
+			for i, p := range d.GetPaths() {
+				attrs := d.getPathAttributes(i)
+				asPath := getAsPath(attrs)
+				types, origin := n.getHijackTypes(owned, length, asPath)
+				for _, kind := range types {
+					counts[kind]++
+					if len(violations) >= maxHijackViolations {
+						continue
+					}
+					v := &HijackViolation{
+						Type:        kind,
+						OwnedPrefix: owned.prefix,
+						Prefix:      d.GetPrefix(),
+						OriginAsn:   origin,
+						NextHop:     getNextHop(attrs),
+						Peer:        getPathPeer(p),
+						Best:        p.GetBest(),
+					}
+					if asPath != nil {
+						v.AsPath = bgp.AsPathString(asPath)
+					}
+					violations = append(violations, v)
+				}
+			}
+		})
+		if err != nil {
+			level.Error(n.logger).Log(
+				"msg", "failed GoBGP query for owned prefix",
+				"prefix", owned.prefix,
+				"error", err.Error(),
+			)
+			n.IncrementErrorCounter()
+			n.recordError("failed GoBGP query for owned prefix "+owned.prefix, err)
+			continue
+		}
+		for _, kind := range hijackTypes {
+			n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+				routerHijackViolationCount,
+				prometheus.GaugeValue,
+				float64(counts[kind]),
+				owned.prefix,
+				owned.family,
+				kind,
+			))
+		}
+	}
+	n.hijackViolations = violations
+}
+
+// getHijackTypes returns the types of violations of a path to an owned
+// prefix, or its more specific prefix of the given length, along with the
+// origin AS of the path. The origin AS is zero when it is ambiguous.
+func (n *RouterNode) getHijackTypes(owned *ownedPrefix, length int, asPath *bgp.PathAttributeAsPath) ([]string, uint32) {
+	types := []string{}
+	if length > owned.length {
+		types = append(types, hijackMoreSpecific)
+	}
+	origin, ok := getOriginAS(asPath, n.localAS)
+	switch {
+	case !ok:
+		origin = 0
+		types = append(types, hijackAmbiguousOrigin)
+	case !owned.allowedAsn[origin]:
+		types = append(types, hijackOriginMismatch)
+	}
+	for _, asn := range getAsPathASNs(asPath) {
+		if n.forbiddenAsns[asn] {
+			types = append(types, hijackForbiddenAsn)
+			break
+		}
+	}
+	return types, origin
+}
+
+// GetHijackViolations returns the violations found by the last hijack
+// detection run.
+func (n *RouterNode) GetHijackViolations() []*HijackViolation {
+	n.RLock()
+	defer n.RUnlock()
+	return n.hijackViolations
+}
@@ -0,0 +1,103 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/osrg/gobgp/v3/pkg/packet/bgp"
+)
+
+func TestParseOwnedPrefix(t *testing.T) {
+	cases := []struct {
+		input   string
+		prefix  string
+		family  string
+		allowed []uint32
+		ok      bool
+	}{
+		{input: "192.0.2.1/24=65000|65001", prefix: "192.0.2.0/24", family: "ipv4", allowed: []uint32{65000, 65001}, ok: true},
+		{input: "2001:db8::/32=65000", prefix: "2001:db8::/32", family: "ipv6", allowed: []uint32{65000}, ok: true},
+		{input: "192.0.2.0/24", ok: false},
+		{input: "192.0.2.0/24=", ok: false},
+		{input: "192.0.2.0/24=AS65000", ok: false},
+		{input: "192.0.2.0=65000", ok: false},
+	}
+	for _, test := range cases {
+		p, err := parseOwnedPrefix(test.input)
+		if !test.ok {
+			if err == nil {
+				t.Errorf("expected error w/ %q, but got none", test.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("expected no error w/ %q, but got %q", test.input, err)
+			continue
+		}
+		if p.prefix != test.prefix || p.family != test.family {
+			t.Errorf("expected %s (%s) w/ %q, but got %s (%s)", test.prefix, test.family, test.input, p.prefix, p.family)
+		}
+		if len(p.allowedAsn) != len(test.allowed) {
+			t.Errorf("expected %d allowed AS numbers w/ %q, but got %d", len(test.allowed), test.input, len(p.allowedAsn))
+		}
+		for _, asn := range test.allowed {
+			if !p.allowedAsn[asn] {
+				t.Errorf("expected AS%d to be allowed w/ %q", asn, test.input)
+			}
+		}
+	}
+}
+
+func TestGetHijackTypes(t *testing.T) {
+	n := &RouterNode{localAS: 65000, forbiddenAsns: map[uint32]bool{65010: true}}
+	owned, err := parseOwnedPrefix("192.0.2.0/24=65001")
+	if err != nil {
+		t.Fatalf("failed parsing owned prefix: %s", err)
+	}
+	asPath := func(params ...bgp.AsPathParamInterface) *bgp.PathAttributeAsPath {
+		return bgp.NewPathAttributeAsPath(params)
+	}
+	seq := func(asns ...uint32) bgp.AsPathParamInterface {
+		return bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_SEQ, asns)
+	}
+	set := func(asns ...uint32) bgp.AsPathParamInterface {
+		return bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_SET, asns)
+	}
+
+	cases := []struct {
+		name   string
+		length int
+		asPath *bgp.PathAttributeAsPath
+		types  []string
+		origin uint32
+	}{
+		{name: "valid", length: 24, asPath: asPath(seq(65002, 65001)), types: []string{}, origin: 65001},
+		{name: "more specific", length: 25, asPath: asPath(seq(65002, 65001)), types: []string{hijackMoreSpecific}, origin: 65001},
+		{name: "origin mismatch", length: 24, asPath: asPath(seq(65002, 65003)), types: []string{hijackOriginMismatch}, origin: 65003},
+		{name: "forbidden asn", length: 24, asPath: asPath(seq(65010, 65001)), types: []string{hijackForbiddenAsn}, origin: 65001},
+		{name: "ambiguous origin", length: 24, asPath: asPath(seq(65002), set(65001, 65003)), types: []string{hijackAmbiguousOrigin}},
+	}
+	for _, test := range cases {
+		types, origin := n.getHijackTypes(owned, test.length, test.asPath)
+		if !reflect.DeepEqual(types, test.types) {
+			t.Errorf("%s: expected violations %v, but got %v", test.name, test.types, types)
+		}
+		if origin != test.origin {
+			t.Errorf("%s: expected origin AS %d, but got %d", test.name, test.origin, origin)
+		}
+	}
+}
@@ -23,17 +23,11 @@ import (
 	"golang.org/x/net/context"
 )
 
-// GetPeers collects information about BGP peers.
-func (n *RouterNode) GetPeers() {
-
-	serverResponse, err := n.client.ListPeer(context.Background(), &gobgpapi.ListPeerRequest{})
+// listPeers returns the BGP peers configured on a GoBGP router.
+func (n *RouterNode) listPeers(ctx context.Context) ([]*gobgpapi.Peer, error) {
+	serverResponse, err := n.client.ListPeer(ctx, &gobgpapi.ListPeerRequest{})
 	if err != nil {
-		level.Error(n.logger).Log(
-			"msg", "GoBGP query for peers failed",
-			"error", err.Error(),
-		)
-		n.IncrementErrorCounter()
-		return
+		return nil, err
 	}
 
 	peers := make([]*gobgpapi.Peer, 0, 1024)
@@ -42,24 +36,27 @@ func (n *RouterNode) GetPeers() {
 		if err == io.EOF {
 			break
 		} else if err != nil {
-			level.Error(n.logger).Log(
-				"msg", "GoBGP get neighbor response parsing failed",
-				"error", err.Error(),
-			)
-			n.IncrementErrorCounter()
-			return
+			return nil, err
 		}
 		peers = append(peers, r.Peer)
 	}
+	return peers, nil
+}
 
+// GetPeers collects information about BGP peers.
+func (n *RouterNode) GetPeers() {
+	peers, err := n.listPeers(n.ctx)
 	if err != nil {
 		level.Error(n.logger).Log(
 			"msg", "GoBGP query for peers failed",
 			"error", err.Error(),
 		)
 		n.IncrementErrorCounter()
+		n.recordError("GoBGP query for peers failed", err)
+		n.peers = nil
 		return
 	}
+	n.peers = peers
 
 	n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
 		routerPeers,
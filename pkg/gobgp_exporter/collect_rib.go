@@ -15,12 +15,12 @@
 package exporter
 
 import (
+	"sort"
 	"strings"
 
 	"github.com/go-kit/log/level"
 	gobgpapi "github.com/osrg/gobgp/v3/api"
 	"github.com/prometheus/client_golang/prometheus"
-	"golang.org/x/net/context"
 )
 
 var addressFamilies = map[string]*gobgpapi.Family{
@@ -82,8 +82,39 @@ var addressFamilies = map[string]*gobgpapi.Family{
 	},
 }
 
+// getFamilyName returns the name of an address family, e.g. ipv4 for IPv4
+// unicast.
+func getFamilyName(f *gobgpapi.Family) string {
+	for name, family := range addressFamilies {
+		if family.GetAfi() == f.GetAfi() && family.GetSafi() == f.GetSafi() {
+			return name
+		}
+	}
+	return strings.ToLower(f.GetAfi().String() + "_" + f.GetSafi().String())
+}
+
+// RibSize is the number of destinations and paths in a route table.
+type RibSize struct {
+	Table        string `json:"table"`
+	Family       string `json:"family"`
+	Destinations uint64 `json:"destinations"`
+	Paths        uint64 `json:"paths"`
+	Accepted     uint64 `json:"accepted"`
+}
+
 // GetRibCounters collects BGP routing information base (RIB) related metrics.
 func (n *RouterNode) GetRibCounters() {
+	sizes := []*RibSize{}
+	defer func() {
+		sort.Slice(sizes, func(i, j int) bool {
+			if sizes[i].Table != sizes[j].Table {
+				return sizes[i].Table < sizes[j].Table
+			}
+			return sizes[i].Family < sizes[j].Family
+		})
+		n.ribSizes = sizes
+	}()
+
 	var tableType gobgpapi.TableType
 	for tableTypeName := range gobgpapi.TableType_value {
 		switch tableTypeName {
@@ -109,7 +140,7 @@ func (n *RouterNode) GetRibCounters() {
 		}
 
 		for addressFamilyName, addressFamily := range addressFamilies {
-			serverResponse, err := n.client.GetTable(context.Background(), &gobgpapi.GetTableRequest{
+			serverResponse, err := n.client.GetTable(n.ctx, &gobgpapi.GetTableRequest{
 				TableType: tableType,
 				Family:    addressFamily,
 				Name:      "",
@@ -123,6 +154,7 @@ func (n *RouterNode) GetRibCounters() {
 					"error", err.Error(),
 				)
 				n.IncrementErrorCounter()
+				n.recordError("failed GoBGP query for route table "+strings.ToLower(tableTypeName)+" "+addressFamilyName, err)
 				continue
 			}
 
@@ -142,6 +174,14 @@ func (n *RouterNode) GetRibCounters() {
 				"response", serverResponse,
 			)
 
+			sizes = append(sizes, &RibSize{
+				Table:        strings.ToLower(tableTypeName),
+				Family:       strings.ToLower(addressFamilyName),
+				Destinations: serverResponse.GetNumDestination(),
+				Paths:        serverResponse.GetNumPath(),
+				Accepted:     serverResponse.GetNumAccepted(),
+			})
+
 			n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
 				routerRibTotalDestinationCount,
 				prometheus.GaugeValue,
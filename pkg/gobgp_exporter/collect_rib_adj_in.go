@@ -0,0 +1,154 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	gobgpapi "github.com/osrg/gobgp/v3/api"
+	"github.com/osrg/gobgp/v3/pkg/packet/bgp"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The reasons of ignoring a received path as invalid.
+const (
+	invalidAsLoop         = "as_loop"
+	invalidOriginatorLoop = "originator_id_loop"
+	invalidRpkiInvalid    = "rpki_invalid"
+)
+
+// adjInAnalyzer accounts the paths received from a peer as accepted,
+// rejected by import policy, or ignored as invalid.
+type adjInAnalyzer struct {
+	target *ribTarget
+	// localAS is the local AS of the session, and allowOwnAS is the
+	// number of its occurrences allowed in the AS path.
+	localAS    uint32
+	allowOwnAS int
+	// routerID is checked against the ORIGINATOR_ID of the paths received
+	// from iBGP peers.
+	routerID string
+	received uint64
+	accepted uint64
+	rejected uint64
+	invalid  map[string]uint64
+}
+
+func newAdjInAnalyzer(n *RouterNode, t *ribTarget) ribAnalyzer {
+	if t.tableType != gobgpapi.TableType_ADJ_IN {
+		return nil
+	}
+	a := &adjInAnalyzer{
+		target:  t,
+		localAS: n.localAS,
+		invalid: map[string]uint64{
+			invalidAsLoop:         0,
+			invalidOriginatorLoop: 0,
+			invalidRpkiInvalid:    0,
+		},
+	}
+	if p := t.neighbor; p != nil {
+		if asn := p.GetState().GetLocalAsn(); asn != 0 {
+			a.localAS = asn
+		} else if asn := p.GetConf().GetLocalAsn(); asn != 0 {
+			a.localAS = asn
+		}
+		a.allowOwnAS = int(p.GetConf().GetAllowOwnAsn())
+		peerAS := p.GetState().GetPeerAsn()
+		if peerAS == 0 {
+			peerAS = p.GetConf().GetPeerAsn()
+		}
+		if peerAS == a.localAS {
+			a.routerID = n.routerID
+		}
+	}
+	return a
+}
+
+// getLoop returns the reason of ignoring a received path with a routing
+// loop, if any, the way GoBGP does on receipt: the local AS found in the AS
+// path more times than allowed, or the router's own ORIGINATOR_ID in a path
+// received from an iBGP peer. The CLUSTER_LIST is checked by GoBGP only
+// when advertising to route reflector clients, so it is not checked here.
+func (a *adjInAnalyzer) getLoop(attrs []bgp.PathAttributeInterface) string {
+	count := 0
+	for _, asn := range getAsPathASNs(getAsPath(attrs)) {
+		if asn == a.localAS {
+			count++
+		}
+	}
+	if count > a.allowOwnAS {
+		return invalidAsLoop
+	}
+	if a.routerID == "" {
+		return ""
+	}
+	for _, attr := range attrs {
+		if v, ok := attr.(*bgp.PathAttributeOriginatorId); ok && v.Value.String() == a.routerID {
+			return invalidOriginatorLoop
+		}
+	}
+	return ""
+}
+
+func (a *adjInAnalyzer) observe(d *ribDestination) {
+	for i, p := range d.GetPaths() {
+		a.received++
+		// The paths with routing loops are ignored before import policy,
+		// while the RPKI invalid paths are rejected by import policy.
+		if loop := a.getLoop(d.getPathAttributes(i)); loop != "" {
+			a.invalid[loop]++
+			continue
+		}
+		switch {
+		case p.GetFiltered() && p.GetValidation().GetState() == gobgpapi.Validation_STATE_INVALID:
+			a.invalid[invalidRpkiInvalid]++
+		case p.GetFiltered():
+			a.rejected++
+		default:
+			a.accepted++
+		}
+	}
+}
+
+func (a *adjInAnalyzer) metrics() []prometheus.Metric {
+	var invalid uint64
+	metrics := []prometheus.Metric{}
+	for reason, count := range a.invalid {
+		invalid += count
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			routerPeerAdjRibInInvalidPathCount,
+			prometheus.GaugeValue,
+			float64(count),
+			a.target.peer,
+			a.target.family,
+			reason,
+		))
+	}
+	for status, count := range map[string]uint64{
+		"received": a.received,
+		"accepted": a.accepted,
+		"rejected": a.rejected,
+		"invalid":  invalid,
+	} {
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			routerPeerAdjRibInPathCount,
+			prometheus.GaugeValue,
+			float64(count),
+			a.target.peer,
+			a.target.family,
+			status,
+		))
+	}
+	return metrics
+}
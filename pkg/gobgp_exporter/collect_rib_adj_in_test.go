@@ -0,0 +1,102 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"testing"
+
+	gobgpapi "github.com/osrg/gobgp/v3/api"
+	"github.com/osrg/gobgp/v3/pkg/packet/bgp"
+)
+
+func TestAdjInLoops(t *testing.T) {
+	n := &RouterNode{localAS: 65000, routerID: "192.0.2.1"}
+	asPath := func(asns ...uint32) bgp.PathAttributeInterface {
+		return bgp.NewPathAttributeAsPath([]bgp.AsPathParamInterface{
+			bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_SEQ, asns),
+		})
+	}
+	ebgp := &gobgpapi.Peer{
+		Conf:  &gobgpapi.PeerConf{PeerAsn: 65001},
+		State: &gobgpapi.PeerState{PeerAsn: 65001},
+	}
+	localAS := &gobgpapi.Peer{
+		Conf:  &gobgpapi.PeerConf{PeerAsn: 65001, LocalAsn: 65100},
+		State: &gobgpapi.PeerState{PeerAsn: 65001, LocalAsn: 65100},
+	}
+	allowOwnAS := &gobgpapi.Peer{
+		Conf:  &gobgpapi.PeerConf{PeerAsn: 65001, AllowOwnAsn: 1},
+		State: &gobgpapi.PeerState{PeerAsn: 65001},
+	}
+	ibgp := &gobgpapi.Peer{
+		Conf:           &gobgpapi.PeerConf{PeerAsn: 65000},
+		State:          &gobgpapi.PeerState{PeerAsn: 65000},
+		RouteReflector: &gobgpapi.RouteReflector{RouteReflectorClusterId: "192.0.2.1"},
+	}
+
+	cases := []struct {
+		name     string
+		neighbor *gobgpapi.Peer
+		attrs    []bgp.PathAttributeInterface
+		reason   string
+	}{
+		{name: "no loop", neighbor: ebgp, attrs: []bgp.PathAttributeInterface{asPath(65001, 65002)}},
+		{name: "as loop", neighbor: ebgp, attrs: []bgp.PathAttributeInterface{asPath(65001, 65000, 65002)}, reason: invalidAsLoop},
+		{name: "as loop of local-as", neighbor: localAS, attrs: []bgp.PathAttributeInterface{asPath(65001, 65100, 65002)}, reason: invalidAsLoop},
+		{name: "global as w/ local-as", neighbor: localAS, attrs: []bgp.PathAttributeInterface{asPath(65001, 65000, 65002)}},
+		{name: "allowed own as", neighbor: allowOwnAS, attrs: []bgp.PathAttributeInterface{asPath(65001, 65000, 65002)}},
+		{name: "own as over limit", neighbor: allowOwnAS, attrs: []bgp.PathAttributeInterface{asPath(65001, 65000, 65000, 65002)}, reason: invalidAsLoop},
+		{
+			name:     "originator id",
+			neighbor: ibgp,
+			attrs:    []bgp.PathAttributeInterface{asPath(65002), bgp.NewPathAttributeOriginatorId("192.0.2.1")},
+			reason:   invalidOriginatorLoop,
+		},
+		{
+			// GoBGP accepts the paths with its own CLUSTER_ID on receipt.
+			name:     "cluster list",
+			neighbor: ibgp,
+			attrs:    []bgp.PathAttributeInterface{asPath(65002), bgp.NewPathAttributeClusterList([]string{"192.0.2.200", "192.0.2.1"})},
+		},
+		{
+			name:     "originator id from ebgp peer",
+			neighbor: ebgp,
+			attrs:    []bgp.PathAttributeInterface{asPath(65001), bgp.NewPathAttributeOriginatorId("192.0.2.1")},
+		},
+	}
+
+	for _, test := range cases {
+		a := newAdjInAnalyzer(n, &ribTarget{
+			tableType: gobgpapi.TableType_ADJ_IN,
+			neighbor:  test.neighbor,
+		}).(*adjInAnalyzer)
+		a.observe(&ribDestination{
+			Destination: &gobgpapi.Destination{Paths: []*gobgpapi.Path{{}}},
+			attributes:  [][]bgp.PathAttributeInterface{test.attrs},
+		})
+		for reason, count := range a.invalid {
+			expected := uint64(0)
+			if reason == test.reason {
+				expected = 1
+			}
+			if count != expected {
+				t.Errorf("%s: expected %d %s paths, but got %d", test.name, expected, reason, count)
+			}
+		}
+		if test.reason == "" && a.accepted != 1 {
+			t.Errorf("%s: expected the path accepted, but got %d accepted", test.name, a.accepted)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"time"
+
+	"github.com/osrg/gobgp/v3/pkg/packet/bgp"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var routeAgeBuckets = []float64{
+	60, 300, 900, 1800, 3600, 3 * 3600, 6 * 3600, 12 * 3600,
+	86400, 3 * 86400, 7 * 86400, 30 * 86400, 90 * 86400,
+}
+
+// routeAgePeerStats are the statistics of the age of the paths received
+// from a peer.
+type routeAgePeerStats struct {
+	counts    []uint64
+	count     uint64
+	sum       float64
+	stale     uint64
+	llgrStale uint64
+}
+
+// routeAgeAnalyzer builds the histogram of the age of the paths in a route
+// table, and counts the paths marked stale by graceful restart (GR) and
+// long-lived graceful restart (LLGR).
+type routeAgeAnalyzer struct {
+	target *ribTarget
+	now    time.Time
+	peers  map[string]*routeAgePeerStats
+}
+
+func newRouteAgeAnalyzer(n *RouterNode, t *ribTarget) ribAnalyzer {
+	a := &routeAgeAnalyzer{
+		target: t,
+		now:    time.Now(),
+		peers:  make(map[string]*routeAgePeerStats),
+	}
+	a.getPeerStats(t.peer)
+	return a
+}
+
+func (a *routeAgeAnalyzer) getPeerStats(peer string) *routeAgePeerStats {
+	if s, exists := a.peers[peer]; exists {
+		return s
+	}
+	s := &routeAgePeerStats{
+		counts: make([]uint64, len(routeAgeBuckets)),
+	}
+	a.peers[peer] = s
+	return s
+}
+
+// hasLlgrStale returns true when a path carries LLGR_STALE community.
+func hasLlgrStale(attrs []bgp.PathAttributeInterface) bool {
+	for _, attr := range attrs {
+		a, ok := attr.(*bgp.PathAttributeCommunities)
+		if !ok {
+			continue
+		}
+		for _, c := range a.Value {
+			if c == uint32(bgp.COMMUNITY_LLGR_STALE) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (a *routeAgeAnalyzer) observe(d *ribDestination) {
+	for i, p := range d.GetPaths() {
+		peer := a.target.peer
+		if peer == "" {
+			peer = getPathPeer(p)
+		}
+		s := a.getPeerStats(peer)
+		if p.GetStale() {
+			s.stale++
+		}
+		if hasLlgrStale(d.getPathAttributes(i)) {
+			s.llgrStale++
+		}
+		if p.GetAge() == nil {
+			continue
+		}
+		age := a.now.Sub(p.GetAge().AsTime()).Seconds()
+		if age < 0 {
+			age = 0
+		}
+		s.count++
+		s.sum += age
+		for j, b := range routeAgeBuckets {
+			if age <= b {
+				s.counts[j]++
+				break
+			}
+		}
+	}
+}
+
+func (a *routeAgeAnalyzer) metrics() []prometheus.Metric {
+	metrics := []prometheus.Metric{}
+	for peer, s := range a.peers {
+		buckets := make(map[float64]uint64, len(routeAgeBuckets))
+		var cumulative uint64
+		for i, b := range routeAgeBuckets {
+			cumulative += s.counts[i]
+			buckets[b] = cumulative
+		}
+		metrics = append(metrics, prometheus.MustNewConstHistogram(
+			routerRibRouteAge,
+			s.count,
+			s.sum,
+			buckets,
+			a.target.table,
+			a.target.family,
+			peer,
+		))
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			routerRibStalePathCount,
+			prometheus.GaugeValue,
+			float64(s.stale),
+			a.target.table,
+			a.target.family,
+			peer,
+		))
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			routerRibLlgrStalePathCount,
+			prometheus.GaugeValue,
+			float64(s.llgrStale),
+			a.target.table,
+			a.target.family,
+			peer,
+		))
+	}
+	return metrics
+}
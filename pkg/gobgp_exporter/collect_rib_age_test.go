@@ -0,0 +1,109 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"testing"
+	"time"
+
+	gobgpapi "github.com/osrg/gobgp/v3/api"
+	"github.com/osrg/gobgp/v3/pkg/packet/bgp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestRouteAgeAnalyzer(t *testing.T) {
+	now := time.Now()
+	paths := []struct {
+		peer  string
+		age   time.Duration
+		stale bool
+		llgr  bool
+	}{
+		{peer: "192.0.2.1", age: 30 * time.Second},
+		{peer: "192.0.2.1", age: 2 * time.Hour, stale: true},
+		// The paths received after the walk began, e.g. due to clock skew,
+		// are of zero age.
+		{peer: "192.0.2.1", age: -time.Minute},
+		// The paths without age are not in the histogram.
+		{peer: "192.0.2.1", llgr: true},
+		// The paths older than the largest bucket are counted in +Inf one.
+		{peer: "192.0.2.2", age: 100 * 24 * time.Hour},
+	}
+	d := &ribDestination{Destination: &gobgpapi.Destination{}}
+	for _, p := range paths {
+		path := &gobgpapi.Path{NeighborIp: p.peer, Stale: p.stale}
+		if p.age != 0 {
+			path.Age = timestamppb.New(now.Add(-p.age))
+		}
+		attrs := []bgp.PathAttributeInterface{}
+		if p.llgr {
+			attrs = append(attrs, bgp.NewPathAttributeCommunities([]uint32{uint32(bgp.COMMUNITY_LLGR_STALE)}))
+		}
+		d.Paths = append(d.Paths, path)
+		d.attributes = append(d.attributes, attrs)
+	}
+	a := newRouteAgeAnalyzer(&RouterNode{}, &ribTarget{table: "global", family: "ipv4"}).(*routeAgeAnalyzer)
+	a.now = now
+	a.observe(d)
+
+	cases := []struct {
+		peer      string
+		count     uint64
+		sum       float64
+		buckets   map[float64]uint64
+		stale     float64
+		llgrStale float64
+	}{
+		{
+			peer:      "192.0.2.1",
+			count:     3,
+			sum:       30 + 2*3600,
+			buckets:   map[float64]uint64{60: 2, 3 * 3600: 1},
+			stale:     1,
+			llgrStale: 1,
+		},
+		{
+			peer:  "192.0.2.2",
+			count: 1,
+			sum:   100 * 86400,
+		},
+	}
+	values := getMetricValues(t, a.metrics())
+	for _, test := range cases {
+		s, exists := a.peers[test.peer]
+		if !exists {
+			t.Errorf("expected route age statistics of %s, but got none", test.peer)
+			continue
+		}
+		if s.count != test.count || s.sum != test.sum {
+			t.Errorf("expected %d paths of %s with total age %v, but got %d with %v", test.count, test.peer, test.sum, s.count, s.sum)
+		}
+		for i, b := range routeAgeBuckets {
+			if s.counts[i] != test.buckets[b] {
+				t.Errorf("expected %d paths of %s in %v bucket, but got %d", test.buckets[b], test.peer, b, s.counts[i])
+			}
+		}
+		labels := "address_family=ipv4,peer=" + test.peer + ",route_table=global"
+		if v := values[routerRibRouteAge][labels]; v != float64(test.count) {
+			t.Errorf("expected route age histogram of %d paths of %s, but got %v", test.count, test.peer, v)
+		}
+		if v := values[routerRibStalePathCount][labels]; v != test.stale {
+			t.Errorf("expected %v stale paths of %s, but got %v", test.stale, test.peer, v)
+		}
+		if v := values[routerRibLlgrStalePathCount][labels]; v != test.llgrStale {
+			t.Errorf("expected %v LLGR stale paths of %s, but got %v", test.llgrStale, test.peer, v)
+		}
+	}
+}
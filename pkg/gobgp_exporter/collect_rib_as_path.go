@@ -0,0 +1,135 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var asPathLengthBuckets = []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 12, 15, 20, 30}
+
+// asPathAnalyzer builds the statistics of AS paths and origin AS numbers
+// of the best paths in a route table.
+type asPathAnalyzer struct {
+	target     *ribTarget
+	localAS    uint32
+	topN       int
+	counts     []uint64
+	count      uint64
+	sum        float64
+	origins    map[uint32]uint64
+	ambiguous  uint64
+	asSetPaths uint64
+	prepended  uint64
+}
+
+func newAsPathAnalyzer(n *RouterNode, t *ribTarget) ribAnalyzer {
+	return &asPathAnalyzer{
+		target:  t,
+		localAS: n.localAS,
+		topN:    n.ribTopN,
+		counts:  make([]uint64, len(asPathLengthBuckets)),
+		origins: make(map[uint32]uint64),
+	}
+}
+
+func (a *asPathAnalyzer) observe(d *ribDestination) {
+	i := d.getBestPath()
+	if i < 0 {
+		return
+	}
+	asPath := getAsPath(d.getPathAttributes(i))
+
+	length := getAsPathLength(asPath)
+	a.count++
+	a.sum += float64(length)
+	for j, b := range asPathLengthBuckets {
+		if float64(length) <= b {
+			a.counts[j]++
+			break
+		}
+	}
+
+	// The origin of a path ending with an AS_SET is ambiguous, the path is
+	// counted as other.
+	if origin, ok := getOriginAS(asPath, a.localAS); ok {
+		a.origins[origin]++
+	} else {
+		a.ambiguous++
+	}
+	if hasAsSet(asPath) {
+		a.asSetPaths++
+	}
+	if hasPrepending(asPath) {
+		a.prepended++
+	}
+}
+
+func (a *asPathAnalyzer) metrics() []prometheus.Metric {
+	labels := a.target.labels()
+	buckets := make(map[float64]uint64, len(asPathLengthBuckets))
+	var cumulative uint64
+	for i, b := range asPathLengthBuckets {
+		cumulative += a.counts[i]
+		buckets[b] = cumulative
+	}
+	metrics := []prometheus.Metric{
+		prometheus.MustNewConstHistogram(
+			routerRibAsPathLength,
+			a.count,
+			a.sum,
+			buckets,
+			labels...,
+		),
+		prometheus.MustNewConstMetric(
+			routerRibOriginAsnCount,
+			prometheus.GaugeValue,
+			float64(len(a.origins)),
+			labels...,
+		),
+		prometheus.MustNewConstMetric(
+			routerRibAsSetPathCount,
+			prometheus.GaugeValue,
+			float64(a.asSetPaths),
+			labels...,
+		),
+		prometheus.MustNewConstMetric(
+			routerRibPrependedPathCount,
+			prometheus.GaugeValue,
+			float64(a.prepended),
+			labels...,
+		),
+	}
+
+	// The top N origin AS numbers by prefix count.
+	origins, other := getTopCounts(a.origins, a.topN, func(i, j uint32) bool { return i < j })
+	for _, asn := range origins {
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			routerRibOriginAsnPrefixCount,
+			prometheus.GaugeValue,
+			float64(a.origins[asn]),
+			append(labels, strconv.FormatUint(uint64(asn), 10))...,
+		))
+	}
+	metrics = append(metrics, prometheus.MustNewConstMetric(
+		routerRibOriginAsnPrefixCount,
+		prometheus.GaugeValue,
+		float64(other+a.ambiguous),
+		append(labels, "other")...,
+	))
+	return metrics
+}
@@ -0,0 +1,67 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"testing"
+
+	gobgpapi "github.com/osrg/gobgp/v3/api"
+	"github.com/osrg/gobgp/v3/pkg/packet/bgp"
+)
+
+func TestAsPathAnalyzerOrigins(t *testing.T) {
+	n := &RouterNode{localAS: 65000, ribTopN: 1}
+	a := newAsPathAnalyzer(n, &ribTarget{table: "global", family: "ipv4"}).(*asPathAnalyzer)
+	asPaths := [][]bgp.AsPathParamInterface{
+		{bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_SEQ, []uint32{65010, 65001})},
+		{bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_SEQ, []uint32{65001})},
+		{bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_SEQ, []uint32{65002})},
+		{
+			// The origin of the path ending with an AS_SET is ambiguous.
+			bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_SEQ, []uint32{65003}),
+			bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_SET, []uint32{65004, 65005}),
+		},
+	}
+	for _, asPath := range asPaths {
+		a.observe(&ribDestination{
+			Destination: &gobgpapi.Destination{Paths: []*gobgpapi.Path{{Best: true}}},
+			attributes:  [][]bgp.PathAttributeInterface{{bgp.NewPathAttributeAsPath(asPath)}},
+		})
+	}
+	values := getMetricValues(t, a.metrics())
+
+	labels := "address_family=ipv4,peer=,route_table=global"
+	if v := values[routerRibOriginAsnCount][labels]; v != 2 {
+		t.Errorf("expected 2 unique origin AS numbers, but got %v", v)
+	}
+	if v := values[routerRibAsSetPathCount][labels]; v != 1 {
+		t.Errorf("expected 1 path with AS_SET, but got %v", v)
+	}
+	// Every best path is counted once, either for its origin AS number or
+	// as other.
+	want := map[string]float64{
+		"address_family=ipv4,origin_asn=65001,peer=,route_table=global": 2,
+		"address_family=ipv4,origin_asn=other,peer=,route_table=global": 2,
+	}
+	got := values[routerRibOriginAsnPrefixCount]
+	if len(got) != len(want) {
+		t.Errorf("expected origin AS prefix counts %v, but got %v", want, got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("expected %v prefixes for %s, but got %v", v, k, got[k])
+		}
+	}
+}
@@ -0,0 +1,287 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// defaultBogons is the built-in list of bogon prefixes and AS numbers. Each
+// line has either a prefix or an AS number range followed by the category.
+// The prefixes of zero length, i.e. default routes, match exactly, while the
+// other prefixes match their more specific prefixes too.
+const defaultBogons = `
+0.0.0.0/0 default
+::/0 default
+0.0.0.0/8 this_network
+10.0.0.0/8 rfc1918
+172.16.0.0/12 rfc1918
+192.168.0.0/16 rfc1918
+100.64.0.0/10 rfc6598
+127.0.0.0/8 loopback
+::1/128 loopback
+169.254.0.0/16 link_local
+fe80::/10 link_local
+192.0.0.0/24 ietf_protocol
+192.0.2.0/24 documentation
+198.51.100.0/24 documentation
+203.0.113.0/24 documentation
+2001:db8::/32 documentation
+198.18.0.0/15 benchmarking
+224.0.0.0/4 multicast
+ff00::/8 multicast
+240.0.0.0/4 reserved
+fc00::/7 unique_local
+0 reserved_asn
+23456 reserved_asn
+64496-64511 documentation_asn
+65536-65551 documentation_asn
+64512-65534 private_asn
+4200000000-4294967294 private_asn
+65535 reserved_asn
+65552-131071 reserved_asn
+4294967295 reserved_asn
+`
+
+type bogonPrefix struct {
+	network  *net.IPNet
+	length   int
+	category string
+}
+
+type bogonAsnRange struct {
+	first    uint32
+	last     uint32
+	category string
+}
+
+// bogonList is the list of bogon prefixes and AS numbers.
+type bogonList struct {
+	prefixes         []*bogonPrefix
+	asns             []*bogonAsnRange
+	prefixCategories []string
+	asnCategories    []string
+}
+
+// parseBogons parses the list of bogon prefixes and AS numbers in the
+// format of defaultBogons.
+func parseBogons(s string) (*bogonList, error) {
+	l := &bogonList{}
+	prefixCategories := make(map[string]bool)
+	asnCategories := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid bogon entry on line %d: %q", lineNumber, line)
+		}
+		if strings.Contains(fields[0], "/") {
+			_, network, err := net.ParseCIDR(fields[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid bogon prefix on line %d: %s", lineNumber, err)
+			}
+			length, _ := network.Mask.Size()
+			l.prefixes = append(l.prefixes, &bogonPrefix{
+				network:  network,
+				length:   length,
+				category: fields[1],
+			})
+			prefixCategories[fields[1]] = true
+			continue
+		}
+		bounds := strings.SplitN(fields[0], "-", 2)
+		first, err := strconv.ParseUint(bounds[0], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bogon AS number on line %d: %q", lineNumber, fields[0])
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = strconv.ParseUint(bounds[1], 10, 32); err != nil || last < first {
+				return nil, fmt.Errorf("invalid bogon AS number range on line %d: %q", lineNumber, fields[0])
+			}
+		}
+		l.asns = append(l.asns, &bogonAsnRange{
+			first:    uint32(first),
+			last:     uint32(last),
+			category: fields[1],
+		})
+		asnCategories[fields[1]] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for category := range prefixCategories {
+		l.prefixCategories = append(l.prefixCategories, category)
+	}
+	sort.Strings(l.prefixCategories)
+	for category := range asnCategories {
+		l.asnCategories = append(l.asnCategories, category)
+	}
+	sort.Strings(l.asnCategories)
+	return l, nil
+}
+
+// loadBogons returns the built-in list of bogons, or the list from a file
+// when the path to the file is provided.
+func loadBogons(fp string) (*bogonList, error) {
+	if fp == "" {
+		return parseBogons(defaultBogons)
+	}
+	content, err := os.ReadFile(filepath.Clean(fp))
+	if err != nil {
+		return nil, err
+	}
+	return parseBogons(string(content))
+}
+
+// getPrefixCategory returns the category of a bogon prefix, or an empty
+// string when the prefix is not a bogon.
+func (l *bogonList) getPrefixCategory(ip net.IP, length int) string {
+	for _, b := range l.prefixes {
+		if len(b.network.IP) != len(ip) {
+			continue
+		}
+		if b.length == 0 {
+			if length == 0 {
+				return b.category
+			}
+			continue
+		}
+		if length >= b.length && b.network.Contains(ip) {
+			return b.category
+		}
+	}
+	return ""
+}
+
+// getAsnCategory returns the category of a bogon AS number, or an empty
+// string when the AS number is not a bogon.
+func (l *bogonList) getAsnCategory(asn uint32) string {
+	for _, b := range l.asns {
+		if asn >= b.first && asn <= b.last {
+			return b.category
+		}
+	}
+	return ""
+}
+
+// bogonAnalyzer counts the paths to bogon prefixes and the paths with
+// bogon AS numbers in AS path on per peer basis.
+type bogonAnalyzer struct {
+	target   *ribTarget
+	bogons   *bogonList
+	prefixes map[string]map[string]uint64
+	asns     map[string]map[string]uint64
+}
+
+func newBogonAnalyzer(n *RouterNode, t *ribTarget) ribAnalyzer {
+	a := &bogonAnalyzer{
+		target:   t,
+		bogons:   n.bogons,
+		prefixes: make(map[string]map[string]uint64),
+		asns:     make(map[string]map[string]uint64),
+	}
+	a.prefixes[t.peer] = make(map[string]uint64)
+	for _, category := range a.bogons.prefixCategories {
+		a.prefixes[t.peer][category] = 0
+	}
+	a.asns[t.peer] = make(map[string]uint64)
+	for _, category := range a.bogons.asnCategories {
+		a.asns[t.peer][category] = 0
+	}
+	return a
+}
+
+func (a *bogonAnalyzer) observe(d *ribDestination) {
+	prefixCategory := ""
+	if ip, network, err := net.ParseCIDR(d.GetPrefix()); err == nil {
+		length, _ := network.Mask.Size()
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+		}
+		prefixCategory = a.bogons.getPrefixCategory(ip, length)
+	}
+	for i, p := range d.GetPaths() {
+		peer := a.target.peer
+		if peer == "" {
+			peer = getPathPeer(p)
+		}
+		if prefixCategory != "" {
+			if _, exists := a.prefixes[peer]; !exists {
+				a.prefixes[peer] = make(map[string]uint64)
+			}
+			a.prefixes[peer][prefixCategory]++
+		}
+		counted := []string{}
+		for _, asn := range getAsPathASNs(getAsPath(d.getPathAttributes(i))) {
+			category := a.bogons.getAsnCategory(asn)
+			if category == "" || containsString(counted, category) {
+				continue
+			}
+			counted = append(counted, category)
+			if _, exists := a.asns[peer]; !exists {
+				a.asns[peer] = make(map[string]uint64)
+			}
+			a.asns[peer][category]++
+		}
+	}
+}
+
+func (a *bogonAnalyzer) metrics() []prometheus.Metric {
+	metrics := []prometheus.Metric{}
+	for peer, counts := range a.prefixes {
+		for category, count := range counts {
+			metrics = append(metrics, prometheus.MustNewConstMetric(
+				routerBogonPrefixPathCount,
+				prometheus.GaugeValue,
+				float64(count),
+				a.target.table,
+				a.target.family,
+				peer,
+				category,
+			))
+		}
+	}
+	for peer, counts := range a.asns {
+		for category, count := range counts {
+			metrics = append(metrics, prometheus.MustNewConstMetric(
+				routerBogonAsnPathCount,
+				prometheus.GaugeValue,
+				float64(count),
+				a.target.table,
+				a.target.family,
+				peer,
+				category,
+			))
+		}
+	}
+	return metrics
+}
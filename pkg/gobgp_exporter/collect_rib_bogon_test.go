@@ -0,0 +1,77 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"net"
+	"testing"
+)
+
+func TestBogons(t *testing.T) {
+	bogons, err := loadBogons("")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	prefixes := []struct {
+		prefix   string
+		category string
+	}{
+		{prefix: "0.0.0.0/0", category: "default"},
+		{prefix: "::/0", category: "default"},
+		{prefix: "10.1.0.0/16", category: "rfc1918"},
+		{prefix: "100.64.1.0/24", category: "rfc6598"},
+		{prefix: "2001:db8:1::/48", category: "documentation"},
+		{prefix: "8.0.0.0/8", category: ""},
+		{prefix: "10.0.0.0/7", category: ""},
+		{prefix: "2001:4860::/32", category: ""},
+	}
+	for _, test := range prefixes {
+		ip, network, err := net.ParseCIDR(test.prefix)
+		if err != nil {
+			t.Fatalf("%s", err)
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+		}
+		length, _ := network.Mask.Size()
+		if category := bogons.getPrefixCategory(ip, length); category != test.category {
+			t.Errorf("expected category %q w/ %s, but got %q", test.category, test.prefix, category)
+		}
+	}
+
+	asns := []struct {
+		asn      uint32
+		category string
+	}{
+		{asn: 0, category: "reserved_asn"},
+		{asn: 64512, category: "private_asn"},
+		{asn: 4200000000, category: "private_asn"},
+		{asn: 23456, category: "reserved_asn"},
+		{asn: 13335, category: ""},
+	}
+	for _, test := range asns {
+		if category := bogons.getAsnCategory(test.asn); category != test.category {
+			t.Errorf("expected category %q w/ AS%d, but got %q", test.category, test.asn, category)
+		}
+	}
+
+	if _, err := parseBogons("10.0.0.0/8"); err == nil {
+		t.Errorf("expected error w/ bogon entry without category")
+	}
+	if _, err := parseBogons("65000-64512 private_asn"); err == nil {
+		t.Errorf("expected error w/ invalid bogon AS number range")
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"hash/fnv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// churnState is the state of a route table kept between polls. The
+// prefixes and their best paths are stored as 64-bit hashes.
+type churnState struct {
+	fingerprints map[uint64]uint64
+	added        uint64
+	withdrawn    uint64
+	changed      uint64
+}
+
+// churnAnalyzer compares the best paths of a route table with the ones seen
+// during the previous poll, and counts added, withdrawn, and changed
+// prefixes.
+type churnAnalyzer struct {
+	target       *ribTarget
+	state        *churnState
+	fingerprints map[uint64]uint64
+}
+
+func newChurnAnalyzer(n *RouterNode, t *ribTarget) ribAnalyzer {
+	if n.churnStates == nil {
+		n.churnStates = make(map[string]*churnState)
+	}
+	key := strings.Join(t.labels(), "|")
+	state, exists := n.churnStates[key]
+	if !exists {
+		state = &churnState{}
+		n.churnStates[key] = state
+	}
+	size := len(state.fingerprints)
+	if size == 0 {
+		size = 1024
+	}
+	return &churnAnalyzer{
+		target:       t,
+		state:        state,
+		fingerprints: make(map[uint64]uint64, size),
+	}
+}
+
+// pruneChurnStates removes the states of the route tables not walked during
+// the current poll, e.g. the Adj-RIB-In tables of the peers gone down.
+func (n *RouterNode) pruneChurnStates(targets []*ribTarget) {
+	walked := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		walked[strings.Join(t.labels(), "|")] = true
+	}
+	for key := range n.churnStates {
+		if !walked[key] {
+			delete(n.churnStates, key)
+		}
+	}
+}
+
+func (a *churnAnalyzer) observe(d *ribDestination) {
+	i := d.getBestPath()
+	if i < 0 {
+		return
+	}
+	p := d.Paths[i]
+
+	key := fnv.New64a()
+	key.Write([]byte(d.GetPrefix())) //nolint:errcheck
+
+	fingerprint := fnv.New64a()
+	fingerprint.Write([]byte(p.GetNeighborIp())) //nolint:errcheck
+	for _, attr := range p.GetPattrs() {
+		fingerprint.Write([]byte(attr.GetTypeUrl())) //nolint:errcheck
+		fingerprint.Write(attr.GetValue())           //nolint:errcheck
+	}
+	a.fingerprints[key.Sum64()] = fingerprint.Sum64()
+}
+
+func (a *churnAnalyzer) metrics() []prometheus.Metric {
+	// The first walk of the route table establishes the baseline.
+	if a.state.fingerprints != nil {
+		for k, v := range a.fingerprints {
+			previous, exists := a.state.fingerprints[k]
+			switch {
+			case !exists:
+				a.state.added++
+			case previous != v:
+				a.state.changed++
+			}
+		}
+		for k := range a.state.fingerprints {
+			if _, exists := a.fingerprints[k]; !exists {
+				a.state.withdrawn++
+			}
+		}
+	}
+	a.state.fingerprints = a.fingerprints
+
+	labels := a.target.labels()
+	return []prometheus.Metric{
+		prometheus.MustNewConstMetric(
+			routerRibAddedCount,
+			prometheus.CounterValue,
+			float64(a.state.added),
+			labels...,
+		),
+		prometheus.MustNewConstMetric(
+			routerRibWithdrawnCount,
+			prometheus.CounterValue,
+			float64(a.state.withdrawn),
+			labels...,
+		),
+		prometheus.MustNewConstMetric(
+			routerRibBestPathChangedCount,
+			prometheus.CounterValue,
+			float64(a.state.changed),
+			labels...,
+		),
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"testing"
+
+	gobgpapi "github.com/osrg/gobgp/v3/api"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestPruneChurnStates(t *testing.T) {
+	n := &RouterNode{}
+	up := &ribTarget{table: "adj_in", family: "ipv4", peer: "192.0.2.1"}
+	down := &ribTarget{table: "adj_in", family: "ipv4", peer: "192.0.2.2"}
+	for _, target := range []*ribTarget{up, down} {
+		newChurnAnalyzer(n, target)
+	}
+	n.pruneChurnStates([]*ribTarget{up})
+	if len(n.churnStates) != 1 {
+		t.Fatalf("expected 1 churn state, but got %d", len(n.churnStates))
+	}
+	if _, exists := n.churnStates["adj_in|ipv4|192.0.2.1"]; !exists {
+		t.Errorf("expected churn state of %s, but got none", up)
+	}
+}
+
+func TestChurnAnalyzer(t *testing.T) {
+	n := &RouterNode{}
+	target := &ribTarget{table: "global", family: "ipv4"}
+	walk := func(routes map[string]string) map[*prometheus.Desc]map[string]float64 {
+		a := newChurnAnalyzer(n, target)
+		for prefix, peer := range routes {
+			a.observe(&ribDestination{Destination: &gobgpapi.Destination{
+				Prefix: prefix,
+				Paths:  []*gobgpapi.Path{{NeighborIp: peer, Best: true}},
+			}})
+		}
+		return getMetricValues(t, a.metrics())
+	}
+	labels := "address_family=ipv4,peer=,route_table=global"
+
+	cases := []struct {
+		name      string
+		routes    map[string]string
+		added     float64
+		withdrawn float64
+		changed   float64
+	}{
+		{
+			// The first walk establishes the baseline.
+			name: "baseline",
+			routes: map[string]string{
+				"192.0.2.0/24":    "10.0.0.1",
+				"198.51.100.0/24": "10.0.0.1",
+				"203.0.113.0/24":  "10.0.0.1",
+			},
+		},
+		{
+			name: "churn",
+			routes: map[string]string{
+				"192.0.2.0/24":    "10.0.0.1",
+				"198.51.100.0/24": "10.0.0.2",
+				"100.64.0.0/10":   "10.0.0.1",
+			},
+			added:     1,
+			withdrawn: 1,
+			changed:   1,
+		},
+		{
+			name: "no churn",
+			routes: map[string]string{
+				"192.0.2.0/24":    "10.0.0.1",
+				"198.51.100.0/24": "10.0.0.2",
+				"100.64.0.0/10":   "10.0.0.1",
+			},
+			added:     1,
+			withdrawn: 1,
+			changed:   1,
+		},
+	}
+	for _, test := range cases {
+		values := walk(test.routes)
+		for desc, expected := range map[*prometheus.Desc]float64{
+			routerRibAddedCount:           test.added,
+			routerRibWithdrawnCount:       test.withdrawn,
+			routerRibBestPathChangedCount: test.changed,
+		} {
+			if value := values[desc][labels]; value != expected {
+				t.Errorf("%s: expected %v for %s, but got %v", test.name, expected, desc, value)
+			}
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/osrg/gobgp/v3/pkg/packet/bgp"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// parseCommunity returns the name of a community in the form returned by
+// getCommunities. It accepts standard (65000:666 or no-export), large
+// (65000:1:2), and route target or route origin extended (rt:65000:100,
+// soo:65000:100) communities. The extended communities of 4-octet AS
+// numbers are named in X.Y notation, e.g. rt:64086.59904:100 for
+// rt:4200000000:100.
+func parseCommunity(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if c, exists := bgp.WellKnownCommunityValueMap[s]; exists {
+		return bgp.WellKnownCommunityNameMap[c], nil
+	}
+	subtypes := map[string]bgp.ExtendedCommunityAttrSubType{
+		"rt:":  bgp.EC_SUBTYPE_ROUTE_TARGET,
+		"soo:": bgp.EC_SUBTYPE_ROUTE_ORIGIN,
+	}
+	for prefix, subtype := range subtypes {
+		if strings.HasPrefix(s, prefix) {
+			c, err := parseExtendedCommunity(subtype, strings.TrimPrefix(s, prefix))
+			if err != nil {
+				return "", fmt.Errorf("invalid extended community %q: %s", s, err)
+			}
+			return getExtendedCommunityName(c), nil
+		}
+	}
+	parts := strings.Split(s, ":")
+	switch len(parts) {
+	case 2:
+		hi, err := strconv.ParseUint(parts[0], 10, 16)
+		if err != nil {
+			return "", fmt.Errorf("invalid community %q", s)
+		}
+		lo, err := strconv.ParseUint(parts[1], 10, 16)
+		if err != nil {
+			return "", fmt.Errorf("invalid community %q", s)
+		}
+		return getStandardCommunityName(uint32(hi<<16 | lo)), nil
+	case 3:
+		for _, part := range parts {
+			if _, err := strconv.ParseUint(part, 10, 32); err != nil {
+				return "", fmt.Errorf("invalid large community %q", s)
+			}
+		}
+		return s, nil
+	}
+	return "", fmt.Errorf("invalid community %q", s)
+}
+
+// parseExtendedCommunity parses the value of a route target or route origin
+// extended community, i.e. ASN:N, X.Y:N, or IPv4:N. GoBGP parses ASN:N
+// as 2-octet AS specific community and does not check the ranges of the
+// values, so that the values are checked here, and the AS numbers above
+// 65535 are passed to GoBGP in X.Y notation of 4-octet AS specific
+// community.
+func parseExtendedCommunity(subtype bgp.ExtendedCommunityAttrSubType, s string) (bgp.ExtendedCommunityInterface, error) {
+	i := strings.LastIndex(s, ":")
+	if i < 0 {
+		return nil, fmt.Errorf("no local administrator")
+	}
+	global, local := s[:i], s[i+1:]
+	localBits := 16
+	if ip := net.ParseIP(global); ip != nil {
+		if ip.To4() == nil {
+			return nil, fmt.Errorf("invalid IPv4 address %q", global)
+		}
+	} else if hi, lo, found := strings.Cut(global, "."); found {
+		if _, err := strconv.ParseUint(hi, 10, 16); err != nil {
+			return nil, fmt.Errorf("invalid AS number %q", global)
+		}
+		if _, err := strconv.ParseUint(lo, 10, 16); err != nil {
+			return nil, fmt.Errorf("invalid AS number %q", global)
+		}
+	} else {
+		asn, err := strconv.ParseUint(global, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AS number %q", global)
+		}
+		if asn > math.MaxUint16 {
+			s = fmt.Sprintf("%d.%d:%s", asn>>16, asn&math.MaxUint16, local)
+		} else {
+			localBits = 32
+		}
+	}
+	if _, err := strconv.ParseUint(local, 10, localBits); err != nil {
+		return nil, fmt.Errorf("invalid local administrator %q", local)
+	}
+	return bgp.ParseExtendedCommunity(subtype, s)
+}
+
+// communityAnalyzer counts the paths carrying the communities from the
+// watch list on per peer basis.
+type communityAnalyzer struct {
+	target      *ribTarget
+	communities map[string]bool
+	counts      map[string]map[string]uint64
+}
+
+func newCommunityAnalyzer(n *RouterNode, t *ribTarget) ribAnalyzer {
+	if len(n.ribCommunities) == 0 {
+		return nil
+	}
+	a := &communityAnalyzer{
+		target:      t,
+		communities: make(map[string]bool),
+		counts:      make(map[string]map[string]uint64),
+	}
+	a.counts[t.peer] = make(map[string]uint64)
+	for _, c := range n.ribCommunities {
+		a.communities[c] = true
+		a.counts[t.peer][c] = 0
+	}
+	return a
+}
+
+func (a *communityAnalyzer) observe(d *ribDestination) {
+	for i, p := range d.GetPaths() {
+		counted := []string{}
+		for _, c := range getCommunities(d.getPathAttributes(i)) {
+			if !a.communities[c] || containsString(counted, c) {
+				continue
+			}
+			counted = append(counted, c)
+			peer := a.target.peer
+			if peer == "" {
+				peer = getPathPeer(p)
+			}
+			if _, exists := a.counts[peer]; !exists {
+				a.counts[peer] = make(map[string]uint64)
+			}
+			a.counts[peer][c]++
+		}
+	}
+}
+
+func (a *communityAnalyzer) metrics() []prometheus.Metric {
+	metrics := []prometheus.Metric{}
+	for peer, counts := range a.counts {
+		for c, count := range counts {
+			metrics = append(metrics, prometheus.MustNewConstMetric(
+				routerRibCommunityPathCount,
+				prometheus.GaugeValue,
+				float64(count),
+				a.target.table,
+				a.target.family,
+				peer,
+				c,
+			))
+		}
+	}
+	return metrics
+}
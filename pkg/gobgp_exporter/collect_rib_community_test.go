@@ -0,0 +1,115 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"testing"
+
+	gobgpapi "github.com/osrg/gobgp/v3/api"
+	"github.com/osrg/gobgp/v3/pkg/packet/bgp"
+)
+
+func TestParseCommunity(t *testing.T) {
+	cases := []struct {
+		community string
+		name      string
+		ok        bool
+	}{
+		{community: "65000:666", name: "65000:666", ok: true},
+		{community: "65535:666", name: "blackhole", ok: true},
+		{community: "No-Export", name: "no-export", ok: true},
+		{community: "65000:1:2", name: "65000:1:2", ok: true},
+		{community: "RT:65000:100", name: "rt:65000:100", ok: true},
+		{community: "soo:192.0.2.1:100", name: "soo:192.0.2.1:100", ok: true},
+		{community: "rt:65000:4294967295", name: "rt:65000:4294967295", ok: true},
+		{community: "rt:4200000000:100", name: "rt:64086.59904:100", ok: true},
+		{community: "rt:64086.59904:100", name: "rt:64086.59904:100", ok: true},
+		{community: "65536:1", ok: false},
+		{community: "rt:65000", ok: false},
+		{community: "rt:foo:100", ok: false},
+		{community: "rt:65000:bar", ok: false},
+		{community: "rt:4200000000:65536", ok: false},
+		{community: "rt:4294967296:100", ok: false},
+		{community: "rt:65536.1:100", ok: false},
+		{community: "soo:192.0.2.1:65536", ok: false},
+		{community: "soo:2001:db8::1:100", ok: false},
+		{community: "foo", ok: false},
+	}
+	for _, test := range cases {
+		name, err := parseCommunity(test.community)
+		if test.ok && err != nil {
+			t.Errorf("expected no error w/ %q, but got %q", test.community, err)
+		}
+		if !test.ok && err == nil {
+			t.Errorf("expected error w/ %q, but got %q", test.community, name)
+		}
+		if test.ok && name != test.name {
+			t.Errorf("expected %q w/ %q, but got %q", test.name, test.community, name)
+		}
+	}
+}
+
+func TestCommunityAnalyzer(t *testing.T) {
+	n := &RouterNode{}
+	for _, s := range []string{"65000:666", "rt:4200000000:100", "soo:192.0.2.1:100", "65000:1:2"} {
+		c, err := parseCommunity(s)
+		if err != nil {
+			t.Fatalf("failed parsing community %q: %s", s, err)
+		}
+		n.ribCommunities = append(n.ribCommunities, c)
+	}
+	a := newCommunityAnalyzer(n, &ribTarget{table: "adj_in", family: "ipv4", peer: "192.0.2.10"})
+	paths := [][]bgp.PathAttributeInterface{
+		{
+			bgp.NewPathAttributeCommunities([]uint32{65000<<16 | 666}),
+			bgp.NewPathAttributeExtendedCommunities([]bgp.ExtendedCommunityInterface{
+				bgp.NewFourOctetAsSpecificExtended(bgp.EC_SUBTYPE_ROUTE_TARGET, 4200000000, 100, true),
+				bgp.NewIPv4AddressSpecificExtended(bgp.EC_SUBTYPE_ROUTE_ORIGIN, "192.0.2.1", 100, true),
+			}),
+		},
+		{
+			bgp.NewPathAttributeExtendedCommunities([]bgp.ExtendedCommunityInterface{
+				// The route origin of the same value is not the route target.
+				bgp.NewFourOctetAsSpecificExtended(bgp.EC_SUBTYPE_ROUTE_ORIGIN, 4200000000, 100, true),
+				// The 2-octet AS number of the same value is not the 4-octet one.
+				bgp.NewTwoOctetAsSpecificExtended(bgp.EC_SUBTYPE_ROUTE_TARGET, 64086, 100, true),
+			}),
+			bgp.NewPathAttributeLargeCommunities([]*bgp.LargeCommunity{bgp.NewLargeCommunity(65000, 1, 2)}),
+		},
+	}
+	d := &ribDestination{Destination: &gobgpapi.Destination{}}
+	for i, attrs := range paths {
+		d.Paths = append(d.Paths, &gobgpapi.Path{NeighborIp: "192.0.2.10", Best: i == 0})
+		d.attributes = append(d.attributes, attrs)
+	}
+	a.observe(d)
+
+	want := map[string]float64{
+		"65000:666":          1,
+		"rt:64086.59904:100": 1,
+		"soo:192.0.2.1:100":  1,
+		"65000:1:2":          1,
+	}
+	got := getMetricValues(t, a.metrics())[routerRibCommunityPathCount]
+	if len(got) != len(want) {
+		t.Errorf("expected community path counts %v, but got %v", want, got)
+	}
+	for c, count := range want {
+		labels := "address_family=ipv4,community=" + c + ",peer=192.0.2.10,route_table=adj_in"
+		if got[labels] != count {
+			t.Errorf("expected %v paths with %s, but got %v", count, c, got[labels])
+		}
+	}
+}
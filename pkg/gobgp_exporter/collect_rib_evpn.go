@@ -0,0 +1,180 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"strconv"
+
+	"github.com/osrg/gobgp/v3/pkg/apiutil"
+	"github.com/osrg/gobgp/v3/pkg/packet/bgp"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var evpnRouteTypes = map[uint8]string{
+	bgp.EVPN_ROUTE_TYPE_ETHERNET_AUTO_DISCOVERY: "ethernet_auto_discovery",
+	bgp.EVPN_ROUTE_TYPE_MAC_IP_ADVERTISEMENT:    "mac_ip_advertisement",
+	bgp.EVPN_INCLUSIVE_MULTICAST_ETHERNET_TAG:   "inclusive_multicast_ethernet_tag",
+	bgp.EVPN_ETHERNET_SEGMENT_ROUTE:             "ethernet_segment",
+	bgp.EVPN_IP_PREFIX:                          "ip_prefix",
+}
+
+// evpnLabels are the optional labels of EVPN route counts.
+var evpnLabels = []string{"rd", "vni"}
+
+// evpnRouteKey is the set of label values an EVPN route is counted under.
+type evpnRouteKey struct {
+	routeType string
+	rd        string
+	vni       string
+}
+
+// getEvpnRouteType returns the name of an EVPN route type.
+func getEvpnRouteType(t uint8) string {
+	if name, exists := evpnRouteTypes[t]; exists {
+		return name
+	}
+	return "type_" + strconv.Itoa(int(t))
+}
+
+// getEvpnVni returns the VNI of an EVPN route. The VNI is carried in the
+// label fields of the NLRI, or in PMSI tunnel attribute of inclusive
+// multicast routes.
+func getEvpnVni(nlri *bgp.EVPNNLRI, attrs []bgp.PathAttributeInterface) string {
+	switch r := nlri.RouteTypeData.(type) {
+	case *bgp.EVPNEthernetAutoDiscoveryRoute:
+		return strconv.FormatUint(uint64(r.Label), 10)
+	case *bgp.EVPNMacIPAdvertisementRoute:
+		if len(r.Labels) > 0 {
+			return strconv.FormatUint(uint64(r.Labels[0]), 10)
+		}
+	case *bgp.EVPNMulticastEthernetTagRoute:
+		for _, attr := range attrs {
+			if a, ok := attr.(*bgp.PathAttributePmsiTunnel); ok {
+				return strconv.FormatUint(uint64(a.Label), 10)
+			}
+		}
+		return strconv.FormatUint(uint64(r.ETag), 10)
+	case *bgp.EVPNIPPrefixRoute:
+		return strconv.FormatUint(uint64(r.Label), 10)
+	}
+	return ""
+}
+
+// evpnAnalyzer counts EVPN routes by route type, and optionally by route
+// distinguisher and VNI.
+type evpnAnalyzer struct {
+	target *ribTarget
+	topN   int
+	rd     bool
+	vni    bool
+	routes map[evpnRouteKey]uint64
+}
+
+func newEvpnAnalyzer(n *RouterNode, t *ribTarget) ribAnalyzer {
+	if t.family != "evpn" {
+		return nil
+	}
+	a := &evpnAnalyzer{
+		target: t,
+		topN:   n.ribTopN,
+		rd:     containsString(n.evpnLabels, "rd"),
+		vni:    containsString(n.evpnLabels, "vni"),
+		routes: make(map[evpnRouteKey]uint64),
+	}
+	for _, name := range evpnRouteTypes {
+		a.routes[evpnRouteKey{routeType: name}] = 0
+	}
+	return a
+}
+
+func (a *evpnAnalyzer) observe(d *ribDestination) {
+	i := d.getBestPath()
+	if i < 0 {
+		return
+	}
+	native, err := apiutil.GetNativeNlri(d.Paths[i])
+	if err != nil {
+		return
+	}
+	nlri, ok := native.(*bgp.EVPNNLRI)
+	if !ok {
+		return
+	}
+	k := evpnRouteKey{routeType: getEvpnRouteType(nlri.RouteType)}
+	if a.rd && nlri.RD() != nil {
+		k.rd = nlri.RD().String()
+	}
+	if a.vni {
+		k.vni = getEvpnVni(nlri, d.getPathAttributes(i))
+	}
+	a.routes[k]++
+}
+
+func (a *evpnAnalyzer) metrics() []prometheus.Metric {
+	metrics := []prometheus.Metric{}
+	if !a.rd && !a.vni {
+		for k, count := range a.routes {
+			metrics = append(metrics, a.newMetric(k, count))
+		}
+		return metrics
+	}
+	// The top N route distinguisher and VNI pairs of each route type.
+	routeTypes := make(map[string]map[evpnRouteKey]uint64)
+	for k, count := range a.routes {
+		if k.rd == "" && k.vni == "" && count == 0 {
+			continue
+		}
+		if routeTypes[k.routeType] == nil {
+			routeTypes[k.routeType] = make(map[evpnRouteKey]uint64)
+		}
+		routeTypes[k.routeType][k] = count
+	}
+	for routeType, routes := range routeTypes {
+		keys, other := getTopCounts(routes, a.topN, func(i, j evpnRouteKey) bool {
+			if i.rd != j.rd {
+				return i.rd < j.rd
+			}
+			return i.vni < j.vni
+		})
+		for _, k := range keys {
+			metrics = append(metrics, a.newMetric(k, routes[k]))
+		}
+		if len(keys) == len(routes) {
+			continue
+		}
+		o := evpnRouteKey{routeType: routeType}
+		if a.rd {
+			o.rd = "other"
+		}
+		if a.vni {
+			o.vni = "other"
+		}
+		metrics = append(metrics, a.newMetric(o, other))
+	}
+	return metrics
+}
+
+func (a *evpnAnalyzer) newMetric(k evpnRouteKey, count uint64) prometheus.Metric {
+	return prometheus.MustNewConstMetric(
+		routerEvpnRouteCount,
+		prometheus.GaugeValue,
+		float64(count),
+		a.target.table,
+		a.target.peer,
+		k.routeType,
+		k.rd,
+		k.vni,
+	)
+}
@@ -0,0 +1,112 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"testing"
+
+	gobgpapi "github.com/osrg/gobgp/v3/api"
+	"github.com/osrg/gobgp/v3/pkg/apiutil"
+	"github.com/osrg/gobgp/v3/pkg/packet/bgp"
+)
+
+func TestEvpnAnalyzer(t *testing.T) {
+	rd := func(s string) bgp.RouteDistinguisherInterface {
+		rd, err := bgp.ParseRouteDistinguisher(s)
+		if err != nil {
+			t.Fatalf("failed parsing route distinguisher %q: %s", s, err)
+		}
+		return rd
+	}
+	esi := bgp.EthernetSegmentIdentifier{}
+	nlris := []*bgp.EVPNNLRI{
+		bgp.NewEVPNMacIPAdvertisementRoute(rd("65000:1"), esi, 0, "aa:bb:cc:dd:ee:01", "10.0.0.1", []uint32{100}),
+		bgp.NewEVPNMacIPAdvertisementRoute(rd("65000:1"), esi, 0, "aa:bb:cc:dd:ee:02", "10.0.0.2", []uint32{100}),
+		bgp.NewEVPNMacIPAdvertisementRoute(rd("65000:2"), esi, 0, "aa:bb:cc:dd:ee:03", "10.0.0.3", []uint32{200}),
+		// The VNI of the inclusive multicast route without PMSI tunnel
+		// attribute is its Ethernet tag.
+		bgp.NewEVPNMulticastEthernetTagRoute(rd("65000:1"), 10, "192.0.2.1"),
+		bgp.NewEVPNIPPrefixRoute(rd("65000:3"), esi, 0, 24, "10.1.0.0", "0.0.0.0", 300),
+	}
+	destinations := []*ribDestination{}
+	for _, nlri := range nlris {
+		an, err := apiutil.MarshalNLRI(nlri)
+		if err != nil {
+			t.Fatalf("failed marshaling NLRI %s: %s", nlri, err)
+		}
+		destinations = append(destinations, &ribDestination{Destination: &gobgpapi.Destination{
+			Paths: []*gobgpapi.Path{{Best: true, Family: addressFamilies["evpn"], Nlri: an}},
+		}})
+	}
+
+	cases := []struct {
+		name   string
+		labels []string
+		counts map[string]float64
+	}{
+		{
+			// The route types without routes are reported with zero count.
+			name: "route type",
+			counts: map[string]float64{
+				"peer=,rd=,route_table=global,route_type=ethernet_auto_discovery,vni=":          0,
+				"peer=,rd=,route_table=global,route_type=mac_ip_advertisement,vni=":             3,
+				"peer=,rd=,route_table=global,route_type=inclusive_multicast_ethernet_tag,vni=": 1,
+				"peer=,rd=,route_table=global,route_type=ethernet_segment,vni=":                 0,
+				"peer=,rd=,route_table=global,route_type=ip_prefix,vni=":                        1,
+			},
+		},
+		{
+			// The top route distinguisher and VNI pair of each route type,
+			// the rest of them are counted as other.
+			name:   "route distinguisher and vni",
+			labels: []string{"rd", "vni"},
+			counts: map[string]float64{
+				"peer=,rd=65000:1,route_table=global,route_type=mac_ip_advertisement,vni=100":            2,
+				"peer=,rd=other,route_table=global,route_type=mac_ip_advertisement,vni=other":            1,
+				"peer=,rd=65000:1,route_table=global,route_type=inclusive_multicast_ethernet_tag,vni=10": 1,
+				"peer=,rd=65000:3,route_table=global,route_type=ip_prefix,vni=300":                       1,
+			},
+		},
+		{
+			name:   "route distinguisher",
+			labels: []string{"rd"},
+			counts: map[string]float64{
+				"peer=,rd=65000:1,route_table=global,route_type=mac_ip_advertisement,vni=":             2,
+				"peer=,rd=other,route_table=global,route_type=mac_ip_advertisement,vni=":               1,
+				"peer=,rd=65000:1,route_table=global,route_type=inclusive_multicast_ethernet_tag,vni=": 1,
+				"peer=,rd=65000:3,route_table=global,route_type=ip_prefix,vni=":                        1,
+			},
+		},
+	}
+	for _, test := range cases {
+		n := &RouterNode{ribTopN: 1, evpnLabels: test.labels}
+		if a := newEvpnAnalyzer(n, &ribTarget{table: "global", family: "ipv4"}); a != nil {
+			t.Fatalf("%s: expected no analyzer of ipv4 route table, but got one", test.name)
+		}
+		a := newEvpnAnalyzer(n, &ribTarget{table: "global", family: "evpn"})
+		for _, d := range destinations {
+			a.observe(d)
+		}
+		got := getMetricValues(t, a.metrics())[routerEvpnRouteCount]
+		if len(got) != len(test.counts) {
+			t.Errorf("%s: expected route counts %v, but got %v", test.name, test.counts, got)
+		}
+		for k, v := range test.counts {
+			if count, exists := got[k]; !exists || count != v {
+				t.Errorf("%s: expected %v routes for %s, but got %v", test.name, v, k, count)
+			}
+		}
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"strings"
+	"time"
+
+	"github.com/osrg/gobgp/v3/pkg/apiutil"
+	"github.com/osrg/gobgp/v3/pkg/packet/bgp"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The actions of FlowSpec rules. The rules without traffic filtering action
+// extended communities accept the traffic.
+var flowspecActions = []string{"accept", "discard", "traffic_rate", "redirect", "traffic_marking", "traffic_action"}
+
+// getFlowspecNlri returns the decoded FlowSpec NLRI of a path.
+func getFlowspecNlri(nlri bgp.AddrPrefixInterface) *bgp.FlowSpecNLRI {
+	switch r := nlri.(type) {
+	case *bgp.FlowSpecIPv4Unicast:
+		return &r.FlowSpecNLRI
+	case *bgp.FlowSpecIPv6Unicast:
+		return &r.FlowSpecNLRI
+	case *bgp.FlowSpecIPv4VPN:
+		return &r.FlowSpecNLRI
+	case *bgp.FlowSpecIPv6VPN:
+		return &r.FlowSpecNLRI
+	case *bgp.FlowSpecL2VPN:
+		return &r.FlowSpecNLRI
+	}
+	return nil
+}
+
+// getFlowspecActions returns the actions of a FlowSpec rule, taken from the
+// traffic filtering action extended communities.
+func getFlowspecActions(attrs []bgp.PathAttributeInterface) []string {
+	actions := []string{}
+	for _, attr := range attrs {
+		a, ok := attr.(*bgp.PathAttributeExtendedCommunities)
+		if !ok {
+			continue
+		}
+		for _, c := range a.Value {
+			action := ""
+			switch ec := c.(type) {
+			case *bgp.TrafficRateExtended:
+				action = "traffic_rate"
+				if ec.Rate == 0 {
+					action = "discard"
+				}
+			case *bgp.RedirectTwoOctetAsSpecificExtended,
+				*bgp.RedirectIPv4AddressSpecificExtended,
+				*bgp.RedirectFourOctetAsSpecificExtended,
+				*bgp.RedirectIPv6AddressSpecificExtended:
+				action = "redirect"
+			case *bgp.TrafficRemarkExtended:
+				action = "traffic_marking"
+			case *bgp.TrafficActionExtended:
+				action = "traffic_action"
+			}
+			if action != "" && !containsString(actions, action) {
+				actions = append(actions, action)
+			}
+		}
+	}
+	if len(actions) == 0 {
+		actions = append(actions, "accept")
+	}
+	return actions
+}
+
+// flowspecPeerStats are the statistics of the FlowSpec rules originated by
+// a peer.
+type flowspecPeerStats struct {
+	rules   uint64
+	actions map[string]uint64
+	oldest  time.Time
+}
+
+// flowspecAnalyzer counts FlowSpec rules by action and by the peer that
+// originated them, and tracks the age of the oldest rule.
+type flowspecAnalyzer struct {
+	target *ribTarget
+	now    time.Time
+	peers  map[string]*flowspecPeerStats
+}
+
+func newFlowspecAnalyzer(n *RouterNode, t *ribTarget) ribAnalyzer {
+	if !strings.Contains(t.family, "flowspec") {
+		return nil
+	}
+	a := &flowspecAnalyzer{
+		target: t,
+		now:    time.Now(),
+		peers:  make(map[string]*flowspecPeerStats),
+	}
+	a.getPeerStats(t.peer)
+	return a
+}
+
+func (a *flowspecAnalyzer) getPeerStats(peer string) *flowspecPeerStats {
+	if s, exists := a.peers[peer]; exists {
+		return s
+	}
+	s := &flowspecPeerStats{
+		actions: make(map[string]uint64),
+	}
+	for _, action := range flowspecActions {
+		s.actions[action] = 0
+	}
+	a.peers[peer] = s
+	return s
+}
+
+func (a *flowspecAnalyzer) observe(d *ribDestination) {
+	for i, p := range d.GetPaths() {
+		nlri, err := apiutil.GetNativeNlri(p)
+		if err != nil || getFlowspecNlri(nlri) == nil {
+			continue
+		}
+		peer := a.target.peer
+		if peer == "" {
+			peer = getPathPeer(p)
+		}
+		s := a.getPeerStats(peer)
+		s.rules++
+		for _, action := range getFlowspecActions(d.getPathAttributes(i)) {
+			s.actions[action]++
+		}
+		if p.GetAge() == nil {
+			continue
+		}
+		if age := p.GetAge().AsTime(); s.oldest.IsZero() || age.Before(s.oldest) {
+			s.oldest = age
+		}
+	}
+}
+
+func (a *flowspecAnalyzer) metrics() []prometheus.Metric {
+	metrics := []prometheus.Metric{}
+	for peer, s := range a.peers {
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			routerFlowspecRuleCount,
+			prometheus.GaugeValue,
+			float64(s.rules),
+			a.target.table,
+			a.target.family,
+			peer,
+		))
+		for action, count := range s.actions {
+			metrics = append(metrics, prometheus.MustNewConstMetric(
+				routerFlowspecActionRuleCount,
+				prometheus.GaugeValue,
+				float64(count),
+				a.target.table,
+				a.target.family,
+				peer,
+				action,
+			))
+		}
+		var age float64
+		if !s.oldest.IsZero() {
+			age = a.now.Sub(s.oldest).Seconds()
+		}
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			routerFlowspecOldestRuleAge,
+			prometheus.GaugeValue,
+			age,
+			a.target.table,
+			a.target.family,
+			peer,
+		))
+	}
+	return metrics
+}
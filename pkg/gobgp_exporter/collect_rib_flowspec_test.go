@@ -0,0 +1,120 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"testing"
+	"time"
+
+	gobgpapi "github.com/osrg/gobgp/v3/api"
+	"github.com/osrg/gobgp/v3/pkg/apiutil"
+	"github.com/osrg/gobgp/v3/pkg/packet/bgp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestFlowspecAnalyzer(t *testing.T) {
+	now := time.Now()
+	rules := []struct {
+		peer        string
+		prefix      string
+		communities []bgp.ExtendedCommunityInterface
+		age         time.Duration
+	}{
+		{
+			peer:        "192.0.2.1",
+			prefix:      "10.0.0.0",
+			communities: []bgp.ExtendedCommunityInterface{bgp.NewTrafficRateExtended(65000, 0)},
+			age:         time.Hour,
+		},
+		{
+			peer:   "192.0.2.1",
+			prefix: "10.0.1.0",
+			communities: []bgp.ExtendedCommunityInterface{
+				bgp.NewTrafficRateExtended(65000, 1000),
+				bgp.NewRedirectTwoOctetAsSpecificExtended(65000, 100),
+				bgp.NewTrafficRemarkExtended(10),
+			},
+			age: 10 * time.Minute,
+		},
+		{
+			// The rule without traffic filtering action accepts the
+			// traffic.
+			peer:   "192.0.2.2",
+			prefix: "10.0.2.0",
+		},
+	}
+	d := &ribDestination{Destination: &gobgpapi.Destination{}}
+	for _, r := range rules {
+		nlri, err := apiutil.MarshalNLRI(bgp.NewFlowSpecIPv4Unicast([]bgp.FlowSpecComponentInterface{
+			bgp.NewFlowSpecDestinationPrefix(bgp.NewIPAddrPrefix(24, r.prefix)),
+		}))
+		if err != nil {
+			t.Fatalf("failed marshaling NLRI of %s: %s", r.prefix, err)
+		}
+		p := &gobgpapi.Path{NeighborIp: r.peer, Family: addressFamilies["ipv4_flowspec"], Nlri: nlri}
+		if r.age > 0 {
+			p.Age = timestamppb.New(now.Add(-r.age))
+		}
+		attrs := []bgp.PathAttributeInterface{}
+		if len(r.communities) > 0 {
+			attrs = append(attrs, bgp.NewPathAttributeExtendedCommunities(r.communities))
+		}
+		d.Paths = append(d.Paths, p)
+		d.attributes = append(d.attributes, attrs)
+	}
+
+	n := &RouterNode{}
+	if a := newFlowspecAnalyzer(n, &ribTarget{table: "global", family: "ipv4"}); a != nil {
+		t.Fatalf("expected no analyzer of ipv4 route table, but got one")
+	}
+	a := newFlowspecAnalyzer(n, &ribTarget{table: "global", family: "ipv4_flowspec"}).(*flowspecAnalyzer)
+	a.now = now
+	a.observe(d)
+	values := getMetricValues(t, a.metrics())
+
+	for peer, count := range map[string]float64{"192.0.2.1": 2, "192.0.2.2": 1} {
+		labels := "address_family=ipv4_flowspec,peer=" + peer + ",route_table=global"
+		if v := values[routerFlowspecRuleCount][labels]; v != count {
+			t.Errorf("expected %v rules of %s, but got %v", count, peer, v)
+		}
+	}
+	actions := []struct {
+		peer   string
+		action string
+		count  float64
+	}{
+		{peer: "192.0.2.1", action: "accept", count: 0},
+		{peer: "192.0.2.1", action: "discard", count: 1},
+		{peer: "192.0.2.1", action: "traffic_rate", count: 1},
+		{peer: "192.0.2.1", action: "redirect", count: 1},
+		{peer: "192.0.2.1", action: "traffic_marking", count: 1},
+		{peer: "192.0.2.1", action: "traffic_action", count: 0},
+		{peer: "192.0.2.2", action: "accept", count: 1},
+		{peer: "192.0.2.2", action: "discard", count: 0},
+	}
+	for _, test := range actions {
+		labels := "action=" + test.action + ",address_family=ipv4_flowspec,peer=" + test.peer + ",route_table=global"
+		if v, exists := values[routerFlowspecActionRuleCount][labels]; !exists || v != test.count {
+			t.Errorf("expected %v %s rules of %s, but got %v", test.count, test.action, test.peer, v)
+		}
+	}
+	// The age of the oldest rule, or zero when the rules lack the age.
+	for peer, age := range map[string]float64{"192.0.2.1": 3600, "192.0.2.2": 0} {
+		labels := "address_family=ipv4_flowspec,peer=" + peer + ",route_table=global"
+		if v := values[routerFlowspecOldestRuleAge][labels]; v != age {
+			t.Errorf("expected oldest rule age %v of %s, but got %v", age, peer, v)
+		}
+	}
+}
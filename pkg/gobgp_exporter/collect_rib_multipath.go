@@ -0,0 +1,154 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"github.com/osrg/gobgp/v3/pkg/packet/bgp"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var pathCountBuckets = []float64{1, 2, 3, 4, 5, 6, 8, 10, 16, 32, 64}
+
+// multipathRank is the set of path properties GoBGP compares when it selects
+// multiple best paths, i.e. the paths of equal rank are used for ECMP.
+type multipathRank struct {
+	local     bool
+	ibgp      bool
+	localPref uint32
+	asPathLen int
+	origin    uint8
+	med       uint32
+}
+
+// getMultipathRank returns the rank of the i-th path of a destination.
+func (d *ribDestination) getMultipathRank(i int, localAS uint32) multipathRank {
+	p := d.Paths[i]
+	r := multipathRank{
+		local:     getPathPeer(p) == "",
+		ibgp:      p.GetSourceAsn() != 0 && p.GetSourceAsn() == localAS,
+		localPref: 100,
+	}
+	for _, attr := range d.getPathAttributes(i) {
+		switch a := attr.(type) {
+		case *bgp.PathAttributeLocalPref:
+			r.localPref = a.Value
+		case *bgp.PathAttributeAsPath:
+			r.asPathLen = getAsPathLength(a)
+		case *bgp.PathAttributeOrigin:
+			r.origin = a.Value
+		case *bgp.PathAttributeMultiExitDisc:
+			r.med = a.Value
+		}
+	}
+	return r
+}
+
+// multipathAnalyzer builds the histogram of the number of paths of the
+// destinations in a route table, and counts the destinations with multiple
+// paths and with multiple best path candidates of equal rank. The paths
+// with unreachable next hop are excluded from the latter counts, as GoBGP
+// excludes them from best path selection.
+type multipathAnalyzer struct {
+	target       *ribTarget
+	localAS      uint32
+	multiplePath bool
+	counts       []uint64
+	count        uint64
+	sum          float64
+	multipath    uint64
+	equalRank    uint64
+}
+
+func newMultipathAnalyzer(n *RouterNode, t *ribTarget) ribAnalyzer {
+	return &multipathAnalyzer{
+		target:       t,
+		localAS:      n.localAS,
+		multiplePath: n.global.GetUseMultiplePaths(),
+		counts:       make([]uint64, len(pathCountBuckets)),
+	}
+}
+
+func (a *multipathAnalyzer) observe(d *ribDestination) {
+	paths := len(d.GetPaths())
+	a.count++
+	a.sum += float64(paths)
+	for i, b := range pathCountBuckets {
+		if float64(paths) <= b {
+			a.counts[i]++
+			break
+		}
+	}
+	valid := 0
+	for _, p := range d.GetPaths() {
+		if !p.GetIsNexthopInvalid() {
+			valid++
+		}
+	}
+	if valid < 2 {
+		return
+	}
+	a.multipath++
+	if !a.multiplePath {
+		return
+	}
+	best := d.getBestPath()
+	if best < 0 || d.Paths[best].GetIsNexthopInvalid() {
+		return
+	}
+	rank := d.getMultipathRank(best, a.localAS)
+	for i, p := range d.GetPaths() {
+		if p.GetIsNexthopInvalid() {
+			continue
+		}
+		if i != best && d.getMultipathRank(i, a.localAS) == rank {
+			a.equalRank++
+			break
+		}
+	}
+}
+
+func (a *multipathAnalyzer) metrics() []prometheus.Metric {
+	buckets := make(map[float64]uint64, len(pathCountBuckets))
+	var cumulative uint64
+	for i, b := range pathCountBuckets {
+		cumulative += a.counts[i]
+		buckets[b] = cumulative
+	}
+	labels := a.target.labels()
+	metrics := []prometheus.Metric{
+		prometheus.MustNewConstHistogram(
+			routerRibDestinationPathCount,
+			a.count,
+			a.sum,
+			buckets,
+			labels...,
+		),
+		prometheus.MustNewConstMetric(
+			routerRibMultipathDestinationCount,
+			prometheus.GaugeValue,
+			float64(a.multipath),
+			labels...,
+		),
+	}
+	if a.multiplePath {
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			routerRibEqualRankDestinationCount,
+			prometheus.GaugeValue,
+			float64(a.equalRank),
+			labels...,
+		))
+	}
+	return metrics
+}
@@ -0,0 +1,72 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"testing"
+
+	gobgpapi "github.com/osrg/gobgp/v3/api"
+	"github.com/osrg/gobgp/v3/pkg/packet/bgp"
+)
+
+func TestMultipathInvalidNexthop(t *testing.T) {
+	n := &RouterNode{localAS: 65000, global: &gobgpapi.Global{UseMultiplePaths: true}}
+	attrs := []bgp.PathAttributeInterface{
+		bgp.NewPathAttributeAsPath([]bgp.AsPathParamInterface{
+			bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_SEQ, []uint32{65001}),
+		}),
+	}
+	cases := []struct {
+		name      string
+		paths     []*gobgpapi.Path
+		multipath uint64
+		equalRank uint64
+	}{
+		{
+			name:      "valid",
+			paths:     []*gobgpapi.Path{{Best: true, NeighborIp: "192.0.2.1"}, {NeighborIp: "192.0.2.2"}},
+			multipath: 1,
+			equalRank: 1,
+		},
+		{
+			name:  "invalid next hop",
+			paths: []*gobgpapi.Path{{Best: true, NeighborIp: "192.0.2.1"}, {NeighborIp: "192.0.2.2", IsNexthopInvalid: true}},
+		},
+		{
+			name: "invalid next hop of best candidate",
+			paths: []*gobgpapi.Path{
+				{Best: true, NeighborIp: "192.0.2.1"},
+				{NeighborIp: "192.0.2.2", IsNexthopInvalid: true},
+				{NeighborIp: "192.0.2.3"},
+			},
+			multipath: 1,
+			equalRank: 1,
+		},
+	}
+	for _, test := range cases {
+		a := newMultipathAnalyzer(n, &ribTarget{}).(*multipathAnalyzer)
+		d := &ribDestination{Destination: &gobgpapi.Destination{Paths: test.paths}}
+		for range test.paths {
+			d.attributes = append(d.attributes, attrs)
+		}
+		a.observe(d)
+		if a.multipath != test.multipath {
+			t.Errorf("%s: expected %d multipath destinations, but got %d", test.name, test.multipath, a.multipath)
+		}
+		if a.equalRank != test.equalRank {
+			t.Errorf("%s: expected %d equal rank destinations, but got %d", test.name, test.equalRank, a.equalRank)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// nextHopAnalyzer builds the inventory of next hops of the paths in a route
+// table.
+type nextHopAnalyzer struct {
+	target   *ribTarget
+	topN     int
+	nextHops map[string]uint64
+	invalid  uint64
+}
+
+func newNextHopAnalyzer(n *RouterNode, t *ribTarget) ribAnalyzer {
+	return &nextHopAnalyzer{
+		target:   t,
+		topN:     n.ribTopN,
+		nextHops: make(map[string]uint64),
+	}
+}
+
+func (a *nextHopAnalyzer) observe(d *ribDestination) {
+	for i, p := range d.GetPaths() {
+		a.nextHops[getNextHop(d.getPathAttributes(i))]++
+		if p.GetIsNexthopInvalid() {
+			a.invalid++
+		}
+	}
+}
+
+func (a *nextHopAnalyzer) metrics() []prometheus.Metric {
+	labels := a.target.labels()
+	metrics := []prometheus.Metric{
+		prometheus.MustNewConstMetric(
+			routerRibNextHopCount,
+			prometheus.GaugeValue,
+			float64(len(a.nextHops)),
+			labels...,
+		),
+		prometheus.MustNewConstMetric(
+			routerRibInvalidNextHopPathCount,
+			prometheus.GaugeValue,
+			float64(a.invalid),
+			labels...,
+		),
+	}
+
+	// The top N next hops by path count.
+	nextHops, other := getTopCounts(a.nextHops, a.topN, func(i, j string) bool { return i < j })
+	for _, nextHop := range nextHops {
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			routerRibNextHopPathCount,
+			prometheus.GaugeValue,
+			float64(a.nextHops[nextHop]),
+			append(labels, nextHop)...,
+		))
+	}
+	metrics = append(metrics, prometheus.MustNewConstMetric(
+		routerRibNextHopPathCount,
+		prometheus.GaugeValue,
+		float64(other),
+		append(labels, "other")...,
+	))
+	return metrics
+}
@@ -0,0 +1,70 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"testing"
+
+	gobgpapi "github.com/osrg/gobgp/v3/api"
+	"github.com/osrg/gobgp/v3/pkg/packet/bgp"
+)
+
+func TestNextHopAnalyzer(t *testing.T) {
+	n := &RouterNode{ribTopN: 2}
+	a := newNextHopAnalyzer(n, &ribTarget{table: "global", family: "ipv4"}).(*nextHopAnalyzer)
+	paths := []struct {
+		nextHop string
+		invalid bool
+	}{
+		{nextHop: "192.0.2.1"},
+		{nextHop: "192.0.2.1"},
+		{nextHop: "192.0.2.1"},
+		{nextHop: "192.0.2.2"},
+		{nextHop: "192.0.2.2", invalid: true},
+		{nextHop: "192.0.2.3"},
+		{nextHop: "192.0.2.4", invalid: true},
+	}
+	for _, p := range paths {
+		a.observe(&ribDestination{
+			Destination: &gobgpapi.Destination{Paths: []*gobgpapi.Path{{IsNexthopInvalid: p.invalid}}},
+			attributes:  [][]bgp.PathAttributeInterface{{bgp.NewPathAttributeNextHop(p.nextHop)}},
+		})
+	}
+	values := getMetricValues(t, a.metrics())
+
+	labels := "address_family=ipv4,peer=,route_table=global"
+	if v := values[routerRibNextHopCount][labels]; v != 4 {
+		t.Errorf("expected 4 next hops, but got %v", v)
+	}
+	if v := values[routerRibInvalidNextHopPathCount][labels]; v != 2 {
+		t.Errorf("expected 2 paths with invalid next hop, but got %v", v)
+	}
+	// The top 2 next hops by path count, the rest of them are counted as
+	// other.
+	want := map[string]float64{
+		"address_family=ipv4,next_hop=192.0.2.1,peer=,route_table=global": 3,
+		"address_family=ipv4,next_hop=192.0.2.2,peer=,route_table=global": 2,
+		"address_family=ipv4,next_hop=other,peer=,route_table=global":     2,
+	}
+	got := values[routerRibNextHopPathCount]
+	if len(got) != len(want) {
+		t.Errorf("expected next hop path counts %v, but got %v", want, got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("expected %v paths for %s, but got %v", v, k, got[k])
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"strconv"
+	"strings"
+
+	gobgpapi "github.com/osrg/gobgp/v3/api"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	ipv4PrefixLengthBuckets = []float64{
+		8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20,
+		21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, 32,
+	}
+	ipv6PrefixLengthBuckets = []float64{
+		16, 20, 24, 28, 29, 32, 36, 40, 44, 48, 52, 56, 60, 64, 96, 112, 120, 124, 127, 128,
+	}
+)
+
+// prefixLengthAnalyzer builds the histogram of prefix lengths of the
+// destinations in a route table.
+type prefixLengthAnalyzer struct {
+	target  *ribTarget
+	buckets []float64
+	counts  []uint64
+	count   uint64
+	sum     float64
+}
+
+func newPrefixLengthAnalyzer(n *RouterNode, t *ribTarget) ribAnalyzer {
+	a := &prefixLengthAnalyzer{
+		target: t,
+	}
+	switch addressFamilies[t.family].Safi {
+	case gobgpapi.Family_SAFI_UNICAST, gobgpapi.Family_SAFI_MPLS_VPN, gobgpapi.Family_SAFI_MPLS_LABEL:
+	default:
+		return nil
+	}
+	switch addressFamilies[t.family].Afi {
+	case gobgpapi.Family_AFI_IP:
+		a.buckets = ipv4PrefixLengthBuckets
+	case gobgpapi.Family_AFI_IP6:
+		a.buckets = ipv6PrefixLengthBuckets
+	default:
+		return nil
+	}
+	a.counts = make([]uint64, len(a.buckets))
+	return a
+}
+
+// getPrefixLength returns the length of a prefix, e.g. 24 for 10.0.0.0/24
+// or 100:100:10.0.0.0/24.
+func getPrefixLength(s string) (int, bool) {
+	i := strings.LastIndex(s, "/")
+	if i < 0 {
+		return 0, false
+	}
+	length, err := strconv.Atoi(s[i+1:])
+	if err != nil || length < 0 || length > 128 {
+		return 0, false
+	}
+	return length, true
+}
+
+func (a *prefixLengthAnalyzer) observe(d *ribDestination) {
+	length, ok := getPrefixLength(d.GetPrefix())
+	if !ok {
+		return
+	}
+	a.count++
+	a.sum += float64(length)
+	for i, b := range a.buckets {
+		if float64(length) <= b {
+			a.counts[i]++
+			break
+		}
+	}
+}
+
+func (a *prefixLengthAnalyzer) metrics() []prometheus.Metric {
+	buckets := make(map[float64]uint64, len(a.buckets))
+	var cumulative uint64
+	for i, b := range a.buckets {
+		cumulative += a.counts[i]
+		buckets[b] = cumulative
+	}
+	return []prometheus.Metric{
+		prometheus.MustNewConstHistogram(
+			routerRibPrefixLength,
+			a.count,
+			a.sum,
+			buckets,
+			a.target.labels()...,
+		),
+	}
+}
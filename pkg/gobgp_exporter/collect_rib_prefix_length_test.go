@@ -0,0 +1,61 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"testing"
+
+	gobgpapi "github.com/osrg/gobgp/v3/api"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestPrefixLengthAnalyzer(t *testing.T) {
+	target := &ribTarget{table: "global", family: "ipv4"}
+	a := newPrefixLengthAnalyzer(nil, target)
+	for _, prefix := range []string{"10.0.0.0/8", "10.1.0.0/24", "10.2.0.0/24", "10.3.0.1/32", "invalid"} {
+		a.observe(&ribDestination{Destination: &gobgpapi.Destination{Prefix: prefix}})
+	}
+
+	metrics := a.metrics()
+	if len(metrics) != 1 {
+		t.Fatalf("expected 1 metric, but got %d", len(metrics))
+	}
+	m := &dto.Metric{}
+	if err := metrics[0].Write(m); err != nil {
+		t.Fatalf("%s", err)
+	}
+	h := m.GetHistogram()
+	if h.GetSampleCount() != 4 {
+		t.Errorf("expected 4 prefixes, but got %d", h.GetSampleCount())
+	}
+	for _, b := range h.GetBucket() {
+		var expected uint64
+		switch {
+		case b.GetUpperBound() >= 32:
+			expected = 4
+		case b.GetUpperBound() >= 24:
+			expected = 3
+		default:
+			expected = 1
+		}
+		if b.GetCumulativeCount() != expected {
+			t.Errorf("expected %d prefixes in bucket /%.0f, but got %d", expected, b.GetUpperBound(), b.GetCumulativeCount())
+		}
+	}
+
+	if a := newPrefixLengthAnalyzer(nil, &ribTarget{table: "global", family: "evpn"}); a != nil {
+		t.Errorf("expected no analyzer for evpn address family")
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/osrg/gobgp/v3/pkg/apiutil"
+	"github.com/osrg/gobgp/v3/pkg/packet/bgp"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// parseRouteDistinguisher returns the canonical form of a route
+// distinguisher, e.g. 65000:100.
+func parseRouteDistinguisher(s string) (string, error) {
+	rd, err := bgp.ParseRouteDistinguisher(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid route distinguisher %q: %s", s, err)
+	}
+	return rd.String(), nil
+}
+
+// parseRouteTarget returns the canonical form of a route target, e.g.
+// 65000:100 for rt:65000:100, or 64086.59904:100 for 4200000000:100.
+func parseRouteTarget(s string) (string, error) {
+	rt, err := parseExtendedCommunity(bgp.EC_SUBTYPE_ROUTE_TARGET, strings.TrimPrefix(s, "rt:"))
+	if err != nil {
+		return "", fmt.Errorf("invalid route target %q: %s", s, err)
+	}
+	return rt.String(), nil
+}
+
+// getRouteTargets returns the route targets of a path.
+func getRouteTargets(attrs []bgp.PathAttributeInterface) []string {
+	routeTargets := []string{}
+	for _, attr := range attrs {
+		a, ok := attr.(*bgp.PathAttributeExtendedCommunities)
+		if !ok {
+			continue
+		}
+		for _, c := range a.Value {
+			if _, subtype := c.GetTypes(); subtype != bgp.EC_SUBTYPE_ROUTE_TARGET {
+				continue
+			}
+			if rt := c.String(); !containsString(routeTargets, rt) {
+				routeTargets = append(routeTargets, rt)
+			}
+		}
+	}
+	return routeTargets
+}
+
+// getBoundedCounts returns the counts of the entries from the allowlist, or
+// the top N entries when the allowlist is empty. The rest of the entries
+// are counted as "other".
+func getBoundedCounts(counts map[string]uint64, allowlist []string, topN int) map[string]uint64 {
+	bounded := map[string]uint64{"other": 0}
+	if len(allowlist) > 0 {
+		for _, k := range allowlist {
+			bounded[k] = 0
+		}
+		for k, count := range counts {
+			if containsString(allowlist, k) {
+				bounded[k] += count
+			} else {
+				bounded["other"] += count
+			}
+		}
+		return bounded
+	}
+	keys, other := getTopCounts(counts, topN, func(i, j string) bool { return i < j })
+	for _, k := range keys {
+		bounded[k] = counts[k]
+	}
+	bounded["other"] = other
+	return bounded
+}
+
+// vpnAnalyzer counts L3VPN routes on per route distinguisher and per route
+// target basis.
+type vpnAnalyzer struct {
+	target              *ribTarget
+	topN                int
+	routeDistinguishers []string
+	routeTargets        []string
+	rdCounts            map[string]uint64
+	rtCounts            map[string]uint64
+}
+
+func newVpnAnalyzer(n *RouterNode, t *ribTarget) ribAnalyzer {
+	if t.family != "ipv4_vpn" && t.family != "ipv6_vpn" {
+		return nil
+	}
+	return &vpnAnalyzer{
+		target:              t,
+		topN:                n.ribTopN,
+		routeDistinguishers: n.vpnRouteDistinguishers,
+		routeTargets:        n.vpnRouteTargets,
+		rdCounts:            make(map[string]uint64),
+		rtCounts:            make(map[string]uint64),
+	}
+}
+
+func (a *vpnAnalyzer) observe(d *ribDestination) {
+	i := d.getBestPath()
+	if i < 0 {
+		return
+	}
+	nlri, err := apiutil.GetNativeNlri(d.Paths[i])
+	if err != nil {
+		return
+	}
+	switch r := nlri.(type) {
+	case *bgp.LabeledVPNIPAddrPrefix:
+		a.rdCounts[r.RD.String()]++
+	case *bgp.LabeledVPNIPv6AddrPrefix:
+		a.rdCounts[r.RD.String()]++
+	}
+	for _, rt := range getRouteTargets(d.getPathAttributes(i)) {
+		a.rtCounts[rt]++
+	}
+}
+
+func (a *vpnAnalyzer) metrics() []prometheus.Metric {
+	metrics := []prometheus.Metric{}
+	for rd, count := range getBoundedCounts(a.rdCounts, a.routeDistinguishers, a.topN) {
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			routerVpnRouteDistinguisherRouteCount,
+			prometheus.GaugeValue,
+			float64(count),
+			append(a.target.labels(), rd)...,
+		))
+	}
+	for rt, count := range getBoundedCounts(a.rtCounts, a.routeTargets, a.topN) {
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			routerVpnRouteTargetRouteCount,
+			prometheus.GaugeValue,
+			float64(count),
+			append(a.target.labels(), rt)...,
+		))
+	}
+	return metrics
+}
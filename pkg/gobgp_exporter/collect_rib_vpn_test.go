@@ -0,0 +1,46 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"testing"
+)
+
+func TestParseRouteTarget(t *testing.T) {
+	cases := []struct {
+		routeTarget string
+		name        string
+		ok          bool
+	}{
+		{routeTarget: "65000:100", name: "65000:100", ok: true},
+		{routeTarget: "rt:65000:100", name: "65000:100", ok: true},
+		{routeTarget: "192.0.2.1:100", name: "192.0.2.1:100", ok: true},
+		{routeTarget: "4200000000:100", name: "64086.59904:100", ok: true},
+		{routeTarget: "4200000000:65536", ok: false},
+		{routeTarget: "65000", ok: false},
+	}
+	for _, test := range cases {
+		name, err := parseRouteTarget(test.routeTarget)
+		if test.ok && err != nil {
+			t.Errorf("expected no error w/ %q, but got %q", test.routeTarget, err)
+		}
+		if !test.ok && err == nil {
+			t.Errorf("expected error w/ %q, but got %q", test.routeTarget, name)
+		}
+		if test.ok && name != test.name {
+			t.Errorf("expected %q w/ %q, but got %q", test.name, test.routeTarget, name)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/go-kit/log/level"
+	gobgpapi "github.com/osrg/gobgp/v3/api"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// parsePrefix returns the canonical form of an IP prefix and its address
+// family, e.g. 10.0.0.0/8 and ipv4 for 10.1.1.1/8.
+func parsePrefix(s string) (string, string, error) {
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid prefix %q: %s", s, err)
+	}
+	if network.IP.To4() != nil {
+		return network.String(), "ipv4", nil
+	}
+	return network.String(), "ipv6", nil
+}
+
+// GetWatchedPrefixes collects the state of the prefixes from the watch list
+// in the global route table.
+func (n *RouterNode) GetWatchedPrefixes() {
+	for _, prefix := range n.ribWatchPrefixes {
+		_, family, _ := parsePrefix(prefix)
+		t := &ribTarget{
+			table:     "global",
+			tableType: gobgpapi.TableType_GLOBAL,
+			family:    family,
+		}
+
+		var pathCount int
+		var bestPath bool
+		var originAS uint32
+		var originKnown bool
+		var asPathLength int
+		var nextHop string
+		err := n.walkRib(t, []*gobgpapi.TableLookupPrefix{
+			{
+				Prefix: prefix,
+				Type:   gobgpapi.TableLookupPrefix_EXACT,
+			},
+		}, func(d *ribDestination) {
+			pathCount += len(d.GetPaths())
+			for i, p := range d.GetPaths() {
+				if !p.GetBest() {
+					continue
+				}
+				attrs := d.getPathAttributes(i)
+				asPath := getAsPath(attrs)
+				bestPath = true
+				originAS, originKnown = getOriginAS(asPath, n.localAS)
+				asPathLength = getAsPathLength(asPath)
+				nextHop = getNextHop(attrs)
+				break
+			}
+		})
+		if err != nil {
+			level.Error(n.logger).Log(
+				"msg", "failed GoBGP query for watched prefix",
+				"prefix", prefix,
+				"error", err.Error(),
+			)
+			n.IncrementErrorCounter()
+			n.recordError("failed GoBGP query for watched prefix "+prefix, err)
+			continue
+		}
+
+		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+			routerWatchedPrefixPresent,
+			prometheus.GaugeValue,
+			boolToFloat(pathCount > 0),
+			prefix,
+			family,
+		))
+		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+			routerWatchedPrefixPathCount,
+			prometheus.GaugeValue,
+			float64(pathCount),
+			prefix,
+			family,
+		))
+		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+			routerWatchedPrefixBestPath,
+			prometheus.GaugeValue,
+			boolToFloat(bestPath),
+			prefix,
+			family,
+		))
+		if !bestPath {
+			continue
+		}
+		// The origin of a path ending with an AS_SET is ambiguous, and it
+		// is not exported.
+		if originKnown {
+			n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+				routerWatchedPrefixOriginAsn,
+				prometheus.GaugeValue,
+				float64(originAS),
+				prefix,
+				family,
+			))
+		}
+		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+			routerWatchedPrefixAsPathLength,
+			prometheus.GaugeValue,
+			float64(asPathLength),
+			prefix,
+			family,
+		))
+		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+			routerWatchedPrefixNextHop,
+			prometheus.GaugeValue,
+			1,
+			prefix,
+			family,
+			nextHop,
+		))
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"testing"
+
+	gobgpapi "github.com/osrg/gobgp/v3/api"
+	"github.com/osrg/gobgp/v3/pkg/apiutil"
+	"github.com/osrg/gobgp/v3/pkg/packet/bgp"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestGetWatchedPrefixes(t *testing.T) {
+	newPath := func(best bool, params ...bgp.AsPathParamInterface) *gobgpapi.Path {
+		pattrs, err := apiutil.MarshalPathAttributes([]bgp.PathAttributeInterface{
+			bgp.NewPathAttributeAsPath(params),
+			bgp.NewPathAttributeNextHop("192.0.2.2"),
+		})
+		if err != nil {
+			t.Fatalf("failed marshaling path attributes: %s", err)
+		}
+		return &gobgpapi.Path{Best: best, Pattrs: pattrs}
+	}
+	seq := bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_SEQ, []uint32{65002, 65001})
+	set := bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_SET, []uint32{65003, 65004})
+
+	labels := "address_family=ipv4,prefix=192.0.2.0/24"
+	cases := []struct {
+		name    string
+		paths   []*gobgpapi.Path
+		values  map[*prometheus.Desc]float64
+		missing []*prometheus.Desc
+		nextHop string
+	}{
+		{
+			name:  "best path",
+			paths: []*gobgpapi.Path{newPath(false, seq, set), newPath(true, seq)},
+			values: map[*prometheus.Desc]float64{
+				routerWatchedPrefixPresent:      1,
+				routerWatchedPrefixPathCount:    2,
+				routerWatchedPrefixBestPath:     1,
+				routerWatchedPrefixOriginAsn:    65001,
+				routerWatchedPrefixAsPathLength: 2,
+			},
+			nextHop: "192.0.2.2",
+		},
+		{
+			// The origin of the path ending with an AS_SET is ambiguous.
+			name:  "ambiguous origin",
+			paths: []*gobgpapi.Path{newPath(true, seq, set)},
+			values: map[*prometheus.Desc]float64{
+				routerWatchedPrefixBestPath:     1,
+				routerWatchedPrefixAsPathLength: 3,
+			},
+			missing: []*prometheus.Desc{routerWatchedPrefixOriginAsn},
+		},
+		{
+			name:  "no best path",
+			paths: []*gobgpapi.Path{newPath(false, seq)},
+			values: map[*prometheus.Desc]float64{
+				routerWatchedPrefixPresent:   1,
+				routerWatchedPrefixPathCount: 1,
+				routerWatchedPrefixBestPath:  0,
+			},
+			missing: []*prometheus.Desc{routerWatchedPrefixOriginAsn, routerWatchedPrefixNextHop},
+		},
+		{
+			name: "absent",
+			values: map[*prometheus.Desc]float64{
+				routerWatchedPrefixPresent:  0,
+				routerWatchedPrefixBestPath: 0,
+			},
+			missing: []*prometheus.Desc{routerWatchedPrefixOriginAsn},
+		},
+	}
+	for _, test := range cases {
+		client := &stubClient{}
+		if test.paths != nil {
+			client.destinations = []*gobgpapi.Destination{{Prefix: "192.0.2.0/24", Paths: test.paths}}
+		}
+		n := newTestRouterNode(t, client)
+		n.localAS = 65000
+		n.ribWatchPrefixes = []string{"192.0.2.0/24"}
+		n.GetWatchedPrefixes()
+
+		values := getMetricValues(t, n.metrics)
+		for desc, value := range test.values {
+			if got, exists := values[desc][labels]; !exists || got != value {
+				t.Errorf("%s: expected %s of %v, but got %v", test.name, desc, value, got)
+			}
+		}
+		if test.nextHop != "" {
+			if _, exists := values[routerWatchedPrefixNextHop]["address_family=ipv4,next_hop="+test.nextHop+",prefix=192.0.2.0/24"]; !exists {
+				t.Errorf("%s: expected next hop %s, but got %v", test.name, test.nextHop, values[routerWatchedPrefixNextHop])
+			}
+		}
+		for _, desc := range test.missing {
+			if _, exists := values[desc]; exists {
+				t.Errorf("%s: expected no %s, but got one", test.name, desc)
+			}
+		}
+	}
+}
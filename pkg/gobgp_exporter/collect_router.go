@@ -0,0 +1,65 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// GetRouterInfo collects the global BGP configuration of a GoBGP router.
+// GoBGP returns only the router ID, AS number, listen port and addresses,
+// and whether multiple paths are enabled in GetBgpResponse. The other
+// global settings, e.g. graceful restart or confederation, are not
+// available over its API.
+func (n *RouterNode) GetRouterInfo() {
+	g := n.global
+
+	listenAddresses := append([]string{}, g.GetListenAddresses()...)
+	sort.Strings(listenAddresses)
+
+	n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		routerInfo,
+		prometheus.GaugeValue,
+		1,
+		g.GetRouterId(),
+		strconv.FormatUint(uint64(g.GetAsn()), 10),
+		strconv.FormatInt(int64(g.GetListenPort()), 10),
+		strings.Join(listenAddresses, ","),
+		strconv.FormatBool(g.GetUseMultiplePaths()),
+	))
+
+	n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		routerListenPort,
+		prometheus.GaugeValue,
+		float64(g.GetListenPort()),
+	))
+
+	n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		routerUseMultiplePaths,
+		prometheus.GaugeValue,
+		boolToFloat(g.GetUseMultiplePaths()),
+	))
+}
@@ -0,0 +1,141 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// baseCollectors are the collectors enabled by default. The router_up,
+// error, and scrape time metrics are exported regardless of them.
+var baseCollectors = map[string]string{
+	"router": "Collect the global BGP configuration of the router.",
+	"peers":  "Collect the state, the timers, and the prefix counters of BGP peers.",
+	"rib":    "Collect the number of destinations and paths in route tables.",
+	"watch":  "Collect the state of the prefixes from the watch list.",
+	"hijack": "Collect the hijack detection violations of owned prefixes.",
+}
+
+// metricCache holds the metrics gathered for a selection of collectors
+// until the next poll.
+type metricCache struct {
+	metrics              []prometheus.Metric
+	nextCollectionTicker int64
+}
+
+// GetCollectors returns the names of all collectors.
+func GetCollectors() []string {
+	names := GetRibCollectors()
+	for name := range baseCollectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetCollectorHelp returns the description of a collector.
+func GetCollectorHelp(name string) string {
+	if help, exists := baseCollectors[name]; exists {
+		return help
+	}
+	return GetRibCollectorHelp(name)
+}
+
+// IsCollectorEnabledByDefault returns true when a collector is enabled
+// unless disabled explicitly.
+func IsCollectorEnabledByDefault(name string) bool {
+	_, exists := baseCollectors[name]
+	return exists
+}
+
+// configureCollectors sets the enabled collectors. The collectors enabled
+// by default are used when none are provided.
+func (n *RouterNode) configureCollectors(opts Options) error {
+	names := opts.Collectors
+	if names == nil {
+		for name := range baseCollectors {
+			names = append(names, name)
+		}
+	}
+	names = append(names, opts.RibCollectors...)
+	n.collectors = []string{}
+	n.ribCollectors = []string{}
+	for _, name := range names {
+		if containsString(n.collectors, name) {
+			continue
+		}
+		if _, exists := ribCollectors[name]; exists {
+			n.ribCollectors = append(n.ribCollectors, name)
+		} else if _, exists := baseCollectors[name]; !exists {
+			return fmt.Errorf("unsupported collector %q", name)
+		}
+		n.collectors = append(n.collectors, name)
+	}
+	sort.Strings(n.collectors)
+	sort.Strings(n.ribCollectors)
+	return nil
+}
+
+// getCollectorSelection returns the enabled collectors selected with the
+// collect[] or exclude[] query parameters of a scrape.
+func (n *RouterNode) getCollectorSelection(collect, exclude []string) ([]string, error) {
+	if len(collect) > 0 && len(exclude) > 0 {
+		return nil, fmt.Errorf("collect[] and exclude[] are mutually exclusive")
+	}
+	for _, name := range append(collect, exclude...) {
+		if !containsString(n.collectors, name) {
+			return nil, fmt.Errorf("collector %q is unsupported or disabled", name)
+		}
+	}
+	if len(collect) == 0 {
+		collect = n.collectors
+	}
+	selection := []string{}
+	for _, name := range collect {
+		if containsString(exclude, name) || containsString(selection, name) {
+			continue
+		}
+		selection = append(selection, name)
+	}
+	sort.Strings(selection)
+	return selection, nil
+}
+
+// collectorSelection is a prometheus.Collector exporting the metrics of
+// the selected collectors of a router.
+type collectorSelection struct {
+	node       *RouterNode
+	collectors []string
+}
+
+// Describe implements prometheus.Collector.
+func (c *collectorSelection) Describe(ch chan<- *prometheus.Desc) {
+	c.node.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *collectorSelection) Collect(ch chan<- prometheus.Metric) {
+	c.node.collect(ch, c.collectors)
+}
+
+// getCacheKey returns the key of the metric cache of a selection of
+// collectors.
+func getCacheKey(collectors []string) string {
+	return strings.Join(collectors, ",")
+}
@@ -0,0 +1,173 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	gobgpapi "github.com/osrg/gobgp/v3/api"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestGetCollectorSelection(t *testing.T) {
+	cases := []struct {
+		name       string
+		collectors []string
+		collect    []string
+		exclude    []string
+		selection  []string
+		ok         bool
+	}{
+		{name: "default", selection: []string{"hijack", "peers", "rib", "router", "watch"}, ok: true},
+		{name: "collect", collect: []string{"router", "peers", "router"}, selection: []string{"peers", "router"}, ok: true},
+		{name: "exclude", exclude: []string{"rib", "watch"}, selection: []string{"hijack", "peers", "router"}, ok: true},
+		{name: "collect and exclude", collect: []string{"router"}, exclude: []string{"rib"}},
+		{name: "unknown collector", collect: []string{"bogus"}},
+		{name: "disabled collector", collect: []string{"prefix_length"}},
+		{name: "disabled collector excluded", exclude: []string{"prefix_length"}},
+		{
+			name:       "enabled rib collector",
+			collectors: []string{"rib", "prefix_length"},
+			collect:    []string{"prefix_length"},
+			selection:  []string{"prefix_length"},
+			ok:         true,
+		},
+	}
+	for _, test := range cases {
+		n := &RouterNode{}
+		if err := n.configureCollectors(Options{Collectors: test.collectors}); err != nil {
+			t.Fatalf("%s: failed configuring collectors: %s", test.name, err)
+		}
+		selection, err := n.getCollectorSelection(test.collect, test.exclude)
+		if !test.ok {
+			if err == nil {
+				t.Errorf("%s: expected error, but got selection %v", test.name, selection)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: expected no error, but got %s", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(selection, test.selection) {
+			t.Errorf("%s: expected selection %v, but got %v", test.name, test.selection, selection)
+		}
+	}
+}
+
+func TestConfigureCollectors(t *testing.T) {
+	n := &RouterNode{}
+	if err := n.configureCollectors(Options{Collectors: []string{"bogus"}}); err == nil {
+		t.Errorf("expected error w/ unknown collector, but got none")
+	}
+	if err := n.configureCollectors(Options{RibCollectors: []string{"prefix_length", "as_path"}}); err != nil {
+		t.Fatalf("failed configuring collectors: %s", err)
+	}
+	if want := []string{"as_path", "hijack", "peers", "prefix_length", "rib", "router", "watch"}; !reflect.DeepEqual(n.collectors, want) {
+		t.Errorf("expected collectors %v, but got %v", want, n.collectors)
+	}
+	if want := []string{"as_path", "prefix_length"}; !reflect.DeepEqual(n.ribCollectors, want) {
+		t.Errorf("expected rib collectors %v, but got %v", want, n.ribCollectors)
+	}
+}
+
+func TestScrapeCollectorSelection(t *testing.T) {
+	client := &stubClient{global: &gobgpapi.Global{Asn: 65000, RouterId: "192.0.2.1"}}
+	n := newTestRouterNode(t, client)
+	n.pollInterval = 60
+	e := &Exporter{
+		Node:         n,
+		authDisabled: true,
+		authFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "auth_failure_count",
+		}, []string{"reason"}),
+		buildInfo: prometheus.NewGauge(prometheus.GaugeOpts{Name: "build_info"}),
+		logger:    log.NewNopLogger(),
+	}
+	cases := []struct {
+		name    string
+		url     string
+		code    int
+		cache   string
+		present []string
+		absent  []string
+	}{
+		{
+			name:    "default",
+			url:     "/metrics",
+			code:    http.StatusOK,
+			cache:   "hijack,peers,rib,router,watch",
+			present: []string{"gobgp_router_info", "gobgp_peer_count"},
+		},
+		{
+			name:    "collect",
+			url:     "/metrics?collect[]=peers",
+			code:    http.StatusOK,
+			cache:   "peers",
+			present: []string{"gobgp_peer_count"},
+			absent:  []string{"gobgp_router_info"},
+		},
+		{
+			name:    "exclude",
+			url:     "/metrics?exclude[]=peers",
+			code:    http.StatusOK,
+			cache:   "hijack,rib,router,watch",
+			present: []string{"gobgp_router_info"},
+			absent:  []string{"gobgp_peer_count"},
+		},
+		{name: "unknown collector", url: "/metrics?collect[]=bogus", code: http.StatusBadRequest},
+		{name: "disabled collector", url: "/metrics?collect[]=prefix_length", code: http.StatusBadRequest},
+	}
+	for _, test := range cases {
+		w := httptest.NewRecorder()
+		e.Scrape(w, httptest.NewRequest(http.MethodGet, test.url, nil))
+		if w.Code != test.code {
+			t.Errorf("%s: expected status %d, but got %d", test.name, test.code, w.Code)
+			continue
+		}
+		if test.code != http.StatusOK {
+			continue
+		}
+		body := w.Body.String()
+		for _, name := range test.present {
+			if !strings.Contains(body, "\n"+name) {
+				t.Errorf("%s: expected %s metric, but got none", test.name, name)
+			}
+		}
+		for _, name := range test.absent {
+			if strings.Contains(body, "\n"+name) {
+				t.Errorf("%s: expected no %s metric, but got one", test.name, name)
+			}
+		}
+		if _, exists := n.caches[test.cache]; !exists {
+			t.Errorf("%s: expected metric cache %q, but got none", test.name, test.cache)
+		}
+	}
+	// The scrapes of each selection are cached separately.
+	if len(n.caches) != 3 {
+		t.Errorf("expected 3 metric caches, but got %d", len(n.caches))
+	}
+	client.global.RouterId = "192.0.2.2"
+	w := httptest.NewRecorder()
+	e.Scrape(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(w.Body.String(), `id="192.0.2.1"`) {
+		t.Errorf("expected the cached metrics of the selection until the next poll, but they were gathered again")
+	}
+}
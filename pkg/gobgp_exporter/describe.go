@@ -27,9 +27,48 @@ func (n *RouterNode) Describe(ch chan<- *prometheus.Desc) {
 	ch <- routerErrors
 	ch <- routerNextScrape
 	ch <- routerScrapeTime
+	ch <- routerInfo
+	ch <- routerListenPort
+	ch <- routerUseMultiplePaths
 	ch <- routerRibTotalDestinationCount
 	ch <- routerRibTotalPathCount
 	ch <- routerRibAcceptedPathCount
+	ch <- routerRibPrefixLength
+	ch <- routerRibAsPathLength
+	ch <- routerRibOriginAsnCount
+	ch <- routerRibOriginAsnPrefixCount
+	ch <- routerRibAsSetPathCount
+	ch <- routerRibPrependedPathCount
+	ch <- routerRibAddedCount
+	ch <- routerRibWithdrawnCount
+	ch <- routerRibBestPathChangedCount
+	ch <- routerRibNextHopCount
+	ch <- routerRibNextHopPathCount
+	ch <- routerRibInvalidNextHopPathCount
+	ch <- routerEvpnRouteCount
+	ch <- routerFlowspecRuleCount
+	ch <- routerFlowspecActionRuleCount
+	ch <- routerFlowspecOldestRuleAge
+	ch <- routerRibRouteAge
+	ch <- routerRibStalePathCount
+	ch <- routerRibLlgrStalePathCount
+	ch <- routerVpnRouteDistinguisherRouteCount
+	ch <- routerVpnRouteTargetRouteCount
+	ch <- routerRibDestinationPathCount
+	ch <- routerRibMultipathDestinationCount
+	ch <- routerRibEqualRankDestinationCount
+	ch <- routerPeerAdjRibInPathCount
+	ch <- routerPeerAdjRibInInvalidPathCount
+	ch <- routerRibCommunityPathCount
+	ch <- routerWatchedPrefixPresent
+	ch <- routerWatchedPrefixPathCount
+	ch <- routerWatchedPrefixBestPath
+	ch <- routerWatchedPrefixOriginAsn
+	ch <- routerWatchedPrefixAsPathLength
+	ch <- routerWatchedPrefixNextHop
+	ch <- routerHijackViolationCount
+	ch <- routerBogonPrefixPathCount
+	ch <- routerBogonAsnPathCount
 	ch <- routerPeers
 	ch <- routerPeer
 	ch <- routerPeerAsn
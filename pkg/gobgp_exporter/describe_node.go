@@ -49,6 +49,21 @@ var (
 		"The amount of time it took to scrape the router.",
 		nil, nil,
 	)
+	routerInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "router", "info"),
+		"The global BGP configuration of GoBGP router.",
+		[]string{"id", "asn", "listen_port", "listen_addresses", "use_multiple_paths"}, nil,
+	)
+	routerListenPort = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "router", "listen_port"),
+		"The TCP port GoBGP router listens on for BGP sessions.",
+		nil, nil,
+	)
+	routerUseMultiplePaths = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "router", "use_multiple_paths"),
+		"Whether multiple paths (ECMP) are enabled (1) or not (0).",
+		nil, nil,
+	)
 	routerPeers = prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, "peer", "count"),
 		"The number of BGP peers",
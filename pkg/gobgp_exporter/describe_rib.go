@@ -36,4 +36,220 @@ var (
 		"The number of accepted paths to destinations on per address family and route table basis",
 		[]string{"route_table", "address_family", "vrf_name"}, nil,
 	)
+
+	routerRibPrefixLength = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "route", "prefix_length"),
+		"The distribution of prefix lengths of destinations on per address family, route table, and peer basis",
+		[]string{"route_table", "address_family", "peer"}, nil,
+	)
+
+	routerRibAsPathLength = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "route", "as_path_length"),
+		"The distribution of AS path lengths of best paths on per address family, route table, and peer basis",
+		[]string{"route_table", "address_family", "peer"}, nil,
+	)
+
+	routerRibOriginAsnCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "route", "origin_asn_count"),
+		"The number of unique origin AS numbers of best paths on per address family, route table, and peer basis",
+		[]string{"route_table", "address_family", "peer"}, nil,
+	)
+
+	routerRibOriginAsnPrefixCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "route", "origin_asn_prefix_count"),
+		"The number of prefixes originated by the top origin AS numbers, the rest of them and the prefixes of ambiguous origin AS are counted as other",
+		[]string{"route_table", "address_family", "peer", "origin_asn"}, nil,
+	)
+
+	routerRibAsSetPathCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "route", "as_set_path_count"),
+		"The number of best paths with AS_SET in AS path on per address family, route table, and peer basis",
+		[]string{"route_table", "address_family", "peer"}, nil,
+	)
+
+	routerRibPrependedPathCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "route", "prepended_path_count"),
+		"The number of best paths with AS path prepending on per address family, route table, and peer basis",
+		[]string{"route_table", "address_family", "peer"}, nil,
+	)
+
+	routerRibAddedCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "route", "added_count"),
+		"The number of prefixes added between polls on per address family, route table, and peer basis",
+		[]string{"route_table", "address_family", "peer"}, nil,
+	)
+
+	routerRibWithdrawnCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "route", "withdrawn_count"),
+		"The number of prefixes withdrawn between polls on per address family, route table, and peer basis",
+		[]string{"route_table", "address_family", "peer"}, nil,
+	)
+
+	routerRibBestPathChangedCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "route", "best_path_changed_count"),
+		"The number of prefixes with best path changed between polls on per address family, route table, and peer basis",
+		[]string{"route_table", "address_family", "peer"}, nil,
+	)
+
+	routerRibNextHopCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "route", "next_hop_count"),
+		"The number of distinct next hops on per address family, route table, and peer basis",
+		[]string{"route_table", "address_family", "peer"}, nil,
+	)
+
+	routerRibNextHopPathCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "route", "next_hop_path_count"),
+		"The number of paths via the top next hops, the rest of them are counted as other",
+		[]string{"route_table", "address_family", "peer", "next_hop"}, nil,
+	)
+
+	routerRibInvalidNextHopPathCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "route", "invalid_next_hop_path_count"),
+		"The number of paths with invalid or unreachable next hop on per address family, route table, and peer basis",
+		[]string{"route_table", "address_family", "peer"}, nil,
+	)
+
+	routerEvpnRouteCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "evpn", "route_count"),
+		"The number of EVPN routes on per route type basis, optionally broken down by route distinguisher and VNI",
+		[]string{"route_table", "peer", "route_type", "rd", "vni"}, nil,
+	)
+
+	routerFlowspecRuleCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "flowspec", "rule_count"),
+		"The number of active FlowSpec rules on per address family, route table, and originating peer basis",
+		[]string{"route_table", "address_family", "peer"}, nil,
+	)
+
+	routerFlowspecActionRuleCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "flowspec", "action_rule_count"),
+		"The number of active FlowSpec rules on per action basis, a rule with multiple actions is counted for each of them",
+		[]string{"route_table", "address_family", "peer", "action"}, nil,
+	)
+
+	routerFlowspecOldestRuleAge = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "flowspec", "oldest_rule_age_seconds"),
+		"The age of the oldest active FlowSpec rule on per address family, route table, and originating peer basis",
+		[]string{"route_table", "address_family", "peer"}, nil,
+	)
+
+	routerRibRouteAge = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "route", "age_seconds"),
+		"The distribution of the time since the last change of paths on per address family, route table, and peer basis",
+		[]string{"route_table", "address_family", "peer"}, nil,
+	)
+
+	routerRibStalePathCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "route", "stale_path_count"),
+		"The number of paths marked stale by graceful restart on per address family, route table, and peer basis",
+		[]string{"route_table", "address_family", "peer"}, nil,
+	)
+
+	routerRibLlgrStalePathCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "route", "llgr_stale_path_count"),
+		"The number of paths carrying LLGR_STALE community of long-lived graceful restart on per address family, route table, and peer basis",
+		[]string{"route_table", "address_family", "peer"}, nil,
+	)
+
+	routerVpnRouteDistinguisherRouteCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "vpn", "route_distinguisher_route_count"),
+		"The number of L3VPN routes on per route distinguisher basis",
+		[]string{"route_table", "address_family", "peer", "rd"}, nil,
+	)
+
+	routerVpnRouteTargetRouteCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "vpn", "route_target_route_count"),
+		"The number of L3VPN routes on per route target basis",
+		[]string{"route_table", "address_family", "peer", "route_target"}, nil,
+	)
+
+	routerRibDestinationPathCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "route", "destination_paths"),
+		"The distribution of the number of paths per destination on per address family, route table, and peer basis",
+		[]string{"route_table", "address_family", "peer"}, nil,
+	)
+
+	routerRibMultipathDestinationCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "route", "multipath_destination_count"),
+		"The number of destinations with more than one path with reachable next hop on per address family, route table, and peer basis",
+		[]string{"route_table", "address_family", "peer"}, nil,
+	)
+
+	routerRibEqualRankDestinationCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "route", "equal_rank_destination_count"),
+		"The number of destinations with multiple best path candidates of equal rank when use-multiple-paths is enabled",
+		[]string{"route_table", "address_family", "peer"}, nil,
+	)
+
+	routerPeerAdjRibInPathCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "peer", "adj_rib_in_path_count"),
+		"The number of paths in Adj-RIB-In on per peer, address family, and status (received, accepted, rejected, invalid) basis",
+		[]string{"peer", "address_family", "status"}, nil,
+	)
+
+	routerPeerAdjRibInInvalidPathCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "peer", "adj_rib_in_invalid_path_count"),
+		"The number of paths in Adj-RIB-In ignored as invalid on per peer, address family, and reason (as_loop, originator_id_loop, rpki_invalid) basis",
+		[]string{"peer", "address_family", "reason"}, nil,
+	)
+
+	routerRibCommunityPathCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "route", "community_path_count"),
+		"The number of paths carrying the community from the watch list on per address family, route table, and peer basis",
+		[]string{"route_table", "address_family", "peer", "community"}, nil,
+	)
+
+	routerWatchedPrefixPresent = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "watched_prefix", "present"),
+		"Whether the prefix from the watch list is present (1) in the global route table or not (0)",
+		[]string{"prefix", "address_family"}, nil,
+	)
+
+	routerWatchedPrefixPathCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "watched_prefix", "path_count"),
+		"The number of paths to the prefix from the watch list",
+		[]string{"prefix", "address_family"}, nil,
+	)
+
+	routerWatchedPrefixBestPath = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "watched_prefix", "best_path"),
+		"Whether the prefix from the watch list has a best path (1) or not (0)",
+		[]string{"prefix", "address_family"}, nil,
+	)
+
+	routerWatchedPrefixOriginAsn = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "watched_prefix", "origin_asn"),
+		"What is the origin AS number of the best path to the prefix from the watch list, omitted when the AS path ends with an AS_SET",
+		[]string{"prefix", "address_family"}, nil,
+	)
+
+	routerWatchedPrefixAsPathLength = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "watched_prefix", "as_path_length"),
+		"What is the AS path length of the best path to the prefix from the watch list",
+		[]string{"prefix", "address_family"}, nil,
+	)
+
+	routerWatchedPrefixNextHop = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "watched_prefix", "next_hop"),
+		"What is the next hop of the best path to the prefix from the watch list",
+		[]string{"prefix", "address_family", "next_hop"}, nil,
+	)
+
+	routerHijackViolationCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "hijack", "violation_count"),
+		"The number of paths to the owned prefix, or its more specific prefixes, violating hijack detection rules on per type (more_specific, origin_mismatch, forbidden_asn, ambiguous_origin) basis",
+		[]string{"owned_prefix", "address_family", "type"}, nil,
+	)
+
+	routerBogonPrefixPathCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "bogon", "prefix_path_count"),
+		"The number of paths to bogon prefixes on per address family, route table, peer, and bogon category basis",
+		[]string{"route_table", "address_family", "peer", "category"}, nil,
+	)
+
+	routerBogonAsnPathCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "bogon", "asn_path_count"),
+		"The number of paths with bogon AS numbers in AS path on per address family, route table, peer, and bogon category basis",
+		[]string{"route_table", "address_family", "peer", "category"}, nil,
+	)
 )
@@ -17,12 +17,14 @@ package exporter
 import (
 	"crypto/tls"
 	"net/http"
+	"runtime"
 	"sync"
 	"time"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/prometheus/common/version"
@@ -49,17 +51,60 @@ type Exporter struct {
 	address      string
 	pollInterval int64
 	Node         *RouterNode
-	Tokens       map[string]bool
+	Tokens       map[string]*Token
+	Users        map[string][]byte
+	authDisabled bool
+	authFailures *prometheus.CounterVec
+	buildInfo    prometheus.Gauge
+	selfHandler  http.Handler
+	lgLimiter    *rateLimiter
 	logger       log.Logger
 }
 
 // Options are the options for the initialization of an instance of the
 // Exporter.
 type Options struct {
-	Address string
-	TLS     *tls.Config
-	Timeout int
-	Logger  log.Logger
+	Address       string
+	TLS           *tls.Config
+	Timeout       int
+	Logger        log.Logger
+	RibTables     []string
+	RibFamilies   []string
+	RibCollectors []string
+	RibTopN       int
+	// Collectors is the list of enabled collectors, including the optional
+	// route table collectors. The collectors enabled by default are used
+	// when nil.
+	Collectors []string
+	// RibCommunities is the watch list of communities, e.g. 65000:666,
+	// no-export, 65000:1:2, or rt:65000:100.
+	RibCommunities []string
+	// RibWatchPrefixes is the watch list of prefixes looked up in the
+	// global route table, e.g. 192.0.2.0/24.
+	RibWatchPrefixes []string
+	// OwnedPrefixes is the list of prefixes owned by the operator along
+	// with the AS numbers allowed to originate them, e.g.
+	// 192.0.2.0/24=65000|65001.
+	OwnedPrefixes []string
+	// ForbiddenAsns is the list of AS numbers not expected in the AS path
+	// of the owned prefixes.
+	ForbiddenAsns []string
+	// BogonFile is the path to the file overriding the built-in list of
+	// bogon prefixes and AS numbers.
+	BogonFile string
+	// EvpnLabels is the list of optional labels, i.e. rd and vni, of EVPN
+	// route counts.
+	EvpnLabels []string
+	// VpnRouteDistinguishers and VpnRouteTargets are the allowlists of
+	// route distinguishers and route targets, e.g. 65000:100, of L3VPN
+	// route counts. The top entries are exported when empty.
+	VpnRouteDistinguishers []string
+	VpnRouteTargets        []string
+	// LookingGlassRateLimit is the number of looking glass requests per
+	// second allowed on per client basis, with bursts of up to
+	// LookingGlassBurst requests. The requests are not limited when zero.
+	LookingGlassRateLimit float64
+	LookingGlassBurst     int
 }
 
 // NewExporter returns an initialized Exporter.
@@ -72,14 +117,59 @@ func NewExporter(opts Options) (*Exporter, error) {
 	e := Exporter{
 		timeout: opts.Timeout,
 		address: opts.Address,
-		Tokens:  make(map[string]bool),
-		logger:  opts.Logger,
+		Tokens:  make(map[string]*Token),
+		Users:   make(map[string][]byte),
+		authFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "exporter",
+			Name:      "auth_failure_count",
+			Help:      "The number of authorization failures on per reason (missing, invalid, expired, forbidden) basis",
+		}, []string{"reason"}),
+		lgLimiter: newRateLimiter(opts.LookingGlassRateLimit, opts.LookingGlassBurst),
+		logger:    opts.Logger,
 	}
+	for _, reason := range authFailureReasons {
+		e.authFailures.WithLabelValues(reason)
+	}
+	e.buildInfo = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "exporter",
+		Name:      "build_info",
+		Help:      "A metric with a constant '1' value labeled by version, revision, branch, build date, and Go version of the exporter",
+		ConstLabels: prometheus.Labels{
+			"version":    GetVersion(),
+			"revision":   GetRevision(),
+			"branch":     gitBranch,
+			"build_date": buildDate,
+			"goversion":  runtime.Version(),
+		},
+	})
+	e.buildInfo.Set(1)
+
+	// The metrics of the exporter itself are kept in a registry of their
+	// own, so that they are not reset on each scrape.
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewGoCollector(),
+		rpcDuration,
+		e.authFailures,
+		e.buildInfo,
+	)
+	e.selfHandler = promhttp.InstrumentMetricHandler(
+		registry, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}),
+	)
 
 	n, err := NewRouterNode(opts.Address, opts.Timeout, opts.TLS, opts.Logger)
 	if err != nil {
 		return nil, err
 	}
+	if err := n.configureRib(opts); err != nil {
+		return nil, err
+	}
+	if err := n.configureCollectors(opts); err != nil {
+		return nil, err
+	}
 	e.Node = n
 	level.Debug(e.logger).Log(
 		"msg", "NewExporter() initialized successfully",
@@ -126,19 +216,48 @@ func (e *Exporter) GetPollInterval() int64 {
 	return e.pollInterval
 }
 
+// Close cancels the in-flight queries to GoBGP and closes the connection
+// to it.
+func (e *Exporter) Close() error {
+	return e.Node.Close()
+}
+
+// ScrapeExporter exposes the metrics of the exporter itself, i.e. the
+// process, Go runtime, HTTP handler, and gRPC request metrics.
+func (e *Exporter) ScrapeExporter(w http.ResponseWriter, r *http.Request) {
+	if !e.authorize(w, r, EndpointMetrics) {
+		return
+	}
+	e.selfHandler.ServeHTTP(w, r)
+}
+
 // Scrape scrapes individual nodes.
 func (e *Exporter) Scrape(w http.ResponseWriter, r *http.Request) {
-	if _, authorized := e.authorize(r); !authorized {
-		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	if !e.authorize(w, r, EndpointMetrics) {
 		return
 	}
 	level.Debug(e.logger).Log(
 		"msg", "calls Scrape()",
 	)
 
+	query := r.URL.Query()
+	collectors, err := e.Node.getCollectorSelection(query["collect[]"], query["exclude[]"])
+	if err != nil {
+		level.Warn(e.logger).Log(
+			"msg", "invalid collector selection",
+			"error", err.Error(),
+		)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	start := time.Now()
 	registry := prometheus.NewRegistry()
-	registry.MustRegister(e.Node)
+	registry.MustRegister(
+		&collectorSelection{node: e.Node, collectors: collectors},
+		e.authFailures,
+		e.buildInfo,
+	)
 	h := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
 	h.ServeHTTP(w, r)
 	duration := time.Since(start).Seconds()
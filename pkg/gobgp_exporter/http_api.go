@@ -0,0 +1,422 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	gobgpapi "github.com/osrg/gobgp/v3/api"
+)
+
+// apiCollectors are the collectors gathering the data served by the API.
+var apiCollectors = []string{"peers", "rib", "router"}
+
+// PeerTimers are the timers of a BGP peer, in seconds.
+type PeerTimers struct {
+	ConnectRetry       uint64 `json:"connect_retry"`
+	HoldTime           uint64 `json:"hold_time"`
+	KeepaliveInterval  uint64 `json:"keepalive_interval"`
+	NegotiatedHoldTime uint64 `json:"negotiated_hold_time"`
+	Uptime             int64  `json:"uptime"`
+	Downtime           int64  `json:"downtime"`
+}
+
+// PeerMessages are the message counters of a BGP peer.
+type PeerMessages struct {
+	Total          uint64 `json:"total"`
+	Update         uint64 `json:"update"`
+	Notification   uint64 `json:"notification"`
+	Open           uint64 `json:"open"`
+	Keepalive      uint64 `json:"keepalive"`
+	Refresh        uint64 `json:"refresh"`
+	WithdrawUpdate uint64 `json:"withdraw_update"`
+	WithdrawPrefix uint64 `json:"withdraw_prefix"`
+}
+
+// PeerFamily are the prefix counters of a BGP peer for an address family.
+type PeerFamily struct {
+	Family     string `json:"family"`
+	Enabled    bool   `json:"enabled"`
+	Received   uint64 `json:"received"`
+	Accepted   uint64 `json:"accepted"`
+	Advertised uint64 `json:"advertised"`
+}
+
+// PeerInfo is the state of a BGP peer.
+type PeerInfo struct {
+	Address          string        `json:"address"`
+	RouterID         string        `json:"router_id"`
+	Description      string        `json:"description"`
+	Asn              uint32        `json:"asn"`
+	LocalAsn         uint32        `json:"local_asn"`
+	Type             string        `json:"type"`
+	AdminState       string        `json:"admin_state"`
+	SessionState     string        `json:"session_state"`
+	Flaps            uint32        `json:"flaps"`
+	OutQueue         uint32        `json:"out_queue"`
+	Timers           *PeerTimers   `json:"timers"`
+	ReceivedMessages *PeerMessages `json:"received_messages"`
+	SentMessages     *PeerMessages `json:"sent_messages"`
+	Families         []*PeerFamily `json:"families"`
+}
+
+// RouterInfo is the state and the global BGP configuration of a router.
+type RouterInfo struct {
+	Address          string   `json:"address"`
+	Up               bool     `json:"up"`
+	Result           string   `json:"result"`
+	Timestamp        string   `json:"timestamp"`
+	Errors           int64    `json:"errors"`
+	RouterID         string   `json:"router_id"`
+	Asn              uint32   `json:"asn"`
+	ListenPort       int32    `json:"listen_port"`
+	ListenAddresses  []string `json:"listen_addresses"`
+	UseMultiplePaths bool     `json:"use_multiple_paths"`
+	// Families are the address families enabled for any peer.
+	Families         []string `json:"families,omitempty"`
+	Peers            *int     `json:"peers,omitempty"`
+	EstablishedPeers *int     `json:"established_peers,omitempty"`
+}
+
+func getPeerMessages(m *gobgpapi.Message) *PeerMessages {
+	return &PeerMessages{
+		Total:          m.GetTotal(),
+		Update:         m.GetUpdate(),
+		Notification:   m.GetNotification(),
+		Open:           m.GetOpen(),
+		Keepalive:      m.GetKeepalive(),
+		Refresh:        m.GetRefresh(),
+		WithdrawUpdate: m.GetWithdrawUpdate(),
+		WithdrawPrefix: m.GetWithdrawPrefix(),
+	}
+}
+
+// getPeerInfo converts the state of a BGP peer returned by GoBGP. The uptime
+// and the downtime are the number of seconds since the session went up and
+// down respectively.
+func getPeerInfo(p *gobgpapi.Peer, now time.Time) *PeerInfo {
+	state := p.GetState()
+	timers := p.GetTimers()
+	info := &PeerInfo{
+		Address:      state.GetNeighborAddress(),
+		RouterID:     state.GetRouterId(),
+		Description:  state.GetDescription(),
+		Asn:          state.GetPeerAsn(),
+		LocalAsn:     state.GetLocalAsn(),
+		Type:         strings.ToLower(state.GetType().String()),
+		AdminState:   strings.ToLower(state.GetAdminState().String()),
+		SessionState: strings.ToLower(state.GetSessionState().String()),
+		Flaps:        state.GetFlops(),
+		OutQueue:     state.GetOutQ(),
+		Timers: &PeerTimers{
+			ConnectRetry:       timers.GetConfig().GetConnectRetry(),
+			HoldTime:           timers.GetConfig().GetHoldTime(),
+			KeepaliveInterval:  timers.GetConfig().GetKeepaliveInterval(),
+			NegotiatedHoldTime: timers.GetState().GetNegotiatedHoldTime(),
+		},
+		ReceivedMessages: getPeerMessages(state.GetMessages().GetReceived()),
+		SentMessages:     getPeerMessages(state.GetMessages().GetSent()),
+		Families:         []*PeerFamily{},
+	}
+	if info.Description == "" {
+		info.Description = p.GetConf().GetDescription()
+	}
+	if info.LocalAsn == 0 {
+		info.LocalAsn = p.GetConf().GetLocalAsn()
+	}
+	established := state.GetSessionState() == gobgpapi.PeerState_ESTABLISHED
+	if t := timers.GetState().GetUptime(); established && t.GetSeconds() > 0 {
+		info.Timers.Uptime = int64(now.Sub(t.AsTime()).Seconds())
+	}
+	if t := timers.GetState().GetDowntime(); !established && t.GetSeconds() > 0 {
+		info.Timers.Downtime = int64(now.Sub(t.AsTime()).Seconds())
+	}
+	for _, afiSafi := range p.GetAfiSafis() {
+		s := afiSafi.GetState()
+		family := s.GetFamily()
+		if family == nil {
+			family = afiSafi.GetConfig().GetFamily()
+		}
+		info.Families = append(info.Families, &PeerFamily{
+			Family:     getFamilyName(family),
+			Enabled:    s.GetEnabled() || afiSafi.GetConfig().GetEnabled(),
+			Received:   s.GetReceived(),
+			Accepted:   s.GetAccepted(),
+			Advertised: s.GetAdvertised(),
+		})
+	}
+	return info
+}
+
+// The errors of the API data lookups.
+var (
+	errCollectorDisabled = errors.New("collector is disabled")
+	errDataUnavailable   = errors.New("data is unavailable, the last query to GoBGP failed")
+)
+
+// refreshAPIData gathers the data served by the API, unless it was gathered
+// during the current poll interval. It returns an error when the collector
+// gathering the data is disabled.
+func (n *RouterNode) refreshAPIData(collector string) error {
+	if !containsString(n.collectors, collector) {
+		return fmt.Errorf("%w: %s", errCollectorDisabled, collector)
+	}
+	collectors := []string{}
+	for _, name := range apiCollectors {
+		if containsString(n.collectors, name) {
+			collectors = append(collectors, name)
+		}
+	}
+	n.gatherMetrics(collectors)
+	return nil
+}
+
+// GetPeerInfo returns the state of BGP peers, optionally filtered by peer
+// address and address family.
+func (n *RouterNode) GetPeerInfo(peers, families []string) ([]*PeerInfo, error) {
+	if err := n.refreshAPIData("peers"); err != nil {
+		return nil, err
+	}
+	n.RLock()
+	defer n.RUnlock()
+	if n.peers == nil {
+		return nil, errDataUnavailable
+	}
+	now := time.Now()
+	result := []*PeerInfo{}
+	for _, p := range n.peers {
+		info := getPeerInfo(p, now)
+		if len(peers) > 0 && !containsString(peers, info.Address) {
+			continue
+		}
+		if len(families) > 0 {
+			selected := []*PeerFamily{}
+			for _, f := range info.Families {
+				if containsString(families, f.Family) {
+					selected = append(selected, f)
+				}
+			}
+			if len(selected) == 0 {
+				continue
+			}
+			info.Families = selected
+		}
+		result = append(result, info)
+	}
+	return result, nil
+}
+
+// GetRibSizes returns the number of destinations and paths in route tables,
+// optionally filtered by route table and address family.
+func (n *RouterNode) GetRibSizes(tables, families []string) ([]*RibSize, error) {
+	if err := n.refreshAPIData("rib"); err != nil {
+		return nil, err
+	}
+	n.RLock()
+	defer n.RUnlock()
+	if n.ribSizes == nil {
+		return nil, errDataUnavailable
+	}
+	result := []*RibSize{}
+	for _, s := range n.ribSizes {
+		if len(tables) > 0 && !containsString(tables, s.Table) {
+			continue
+		}
+		if len(families) > 0 && !containsString(families, s.Family) {
+			continue
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// GetRouterState returns the state and the global BGP configuration of the
+// router. The address families and the peer counts are derived from the
+// peers, and omitted when the peers collector is disabled.
+func (n *RouterNode) GetRouterState() (*RouterInfo, error) {
+	if err := n.refreshAPIData("router"); err != nil {
+		return nil, err
+	}
+	n.RLock()
+	defer n.RUnlock()
+	if n.global == nil {
+		return nil, errDataUnavailable
+	}
+	n.errorsLocker.RLock()
+	defer n.errorsLocker.RUnlock()
+	g := n.global
+	info := &RouterInfo{
+		Address:          n.address,
+		Up:               n.connected,
+		Result:           n.result,
+		Timestamp:        n.timestamp,
+		Errors:           n.errors,
+		RouterID:         g.GetRouterId(),
+		Asn:              g.GetAsn(),
+		ListenPort:       g.GetListenPort(),
+		ListenAddresses:  append([]string{}, g.GetListenAddresses()...),
+		UseMultiplePaths: g.GetUseMultiplePaths(),
+	}
+	if n.peers == nil {
+		return info, nil
+	}
+	peers := len(n.peers)
+	established := 0
+	families := make(map[string]bool)
+	for _, p := range n.peers {
+		if p.GetState().GetSessionState() == gobgpapi.PeerState_ESTABLISHED {
+			established++
+		}
+		for _, afiSafi := range p.GetAfiSafis() {
+			if afiSafi.GetConfig().GetEnabled() || afiSafi.GetState().GetEnabled() {
+				families[getFamilyName(afiSafi.GetConfig().GetFamily())] = true
+			}
+		}
+	}
+	info.Peers = &peers
+	info.EstablishedPeers = &established
+	info.Families = []string{}
+	for family := range families {
+		info.Families = append(info.Families, family)
+	}
+	sort.Strings(info.Families)
+	return info, nil
+}
+
+// getQueryList returns the values of a query parameter. The parameter may
+// be repeated, and each value may be a comma-separated list.
+func getQueryList(r *http.Request, key string) []string {
+	values := []string{}
+	for _, v := range r.URL.Query()[key] {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
+
+// validateFamilies returns an error when an address family is unsupported.
+func validateFamilies(families []string) error {
+	for _, s := range families {
+		if _, exists := addressFamilies[s]; !exists {
+			return fmt.Errorf("unsupported address family %q", s)
+		}
+	}
+	return nil
+}
+
+// ribCounterTables are the route tables the sizes of are collected by the
+// rib collector.
+var ribCounterTables = []string{"global", "local"}
+
+// validateTables returns an error when a route table is unsupported.
+func validateTables(tables []string) error {
+	for _, s := range tables {
+		if !containsString(ribCounterTables, s) {
+			return fmt.Errorf("unsupported route table %q", s)
+		}
+	}
+	return nil
+}
+
+// getAPIErrorCode returns the HTTP status code of an API data lookup error.
+func getAPIErrorCode(err error) int {
+	if errors.Is(err, errCollectorDisabled) {
+		return http.StatusNotFound
+	}
+	return http.StatusServiceUnavailable
+}
+
+// writeJSON writes a JSON response.
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v) //nolint:errcheck
+}
+
+// writeJSONError writes a JSON response with an error message.
+func writeJSONError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
+
+// APIPeers returns the state, the timers, and the prefix counters of BGP
+// peers as JSON. The peers are filtered with the peer and family query
+// parameters.
+func (e *Exporter) APIPeers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache, max-age=0, must-revalidate, no-store")
+	if !e.authorize(w, r, EndpointAPI) {
+		return
+	}
+	families := getQueryList(r, "family")
+	if err := validateFamilies(families); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	peers, err := e.Node.GetPeerInfo(getQueryList(r, "peer"), families)
+	if err != nil {
+		writeJSONError(w, getAPIErrorCode(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, peers)
+}
+
+// APIRib returns the number of destinations and paths in route tables as
+// JSON. The route tables are filtered with the table and family query
+// parameters.
+func (e *Exporter) APIRib(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache, max-age=0, must-revalidate, no-store")
+	if !e.authorize(w, r, EndpointAPI) {
+		return
+	}
+	tables := getQueryList(r, "table")
+	if err := validateTables(tables); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	families := getQueryList(r, "family")
+	if err := validateFamilies(families); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	sizes, err := e.Node.GetRibSizes(tables, families)
+	if err != nil {
+		writeJSONError(w, getAPIErrorCode(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sizes)
+}
+
+// APIRouter returns the state and the global BGP configuration of the
+// router as JSON.
+func (e *Exporter) APIRouter(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache, max-age=0, must-revalidate, no-store")
+	if !e.authorize(w, r, EndpointAPI) {
+		return
+	}
+	info, err := e.Node.GetRouterState()
+	if err != nil {
+		writeJSONError(w, getAPIErrorCode(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
@@ -0,0 +1,245 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	gobgpapi "github.com/osrg/gobgp/v3/api"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// newTestPeer returns a peer with the address families enabled, each with
+// the given number of received prefixes.
+func newTestPeer(address string, state gobgpapi.PeerState_SessionState, families map[string]uint64) *gobgpapi.Peer {
+	p := &gobgpapi.Peer{
+		Conf:  &gobgpapi.PeerConf{NeighborAddress: address, PeerAsn: 65001},
+		State: &gobgpapi.PeerState{NeighborAddress: address, PeerAsn: 65001, SessionState: state},
+	}
+	for name, received := range families {
+		p.AfiSafis = append(p.AfiSafis, &gobgpapi.AfiSafi{
+			Config: &gobgpapi.AfiSafiConfig{Family: addressFamilies[name], Enabled: true},
+			State:  &gobgpapi.AfiSafiState{Family: addressFamilies[name], Enabled: true, Received: received},
+		})
+	}
+	return p
+}
+
+func TestGetPeerInfo(t *testing.T) {
+	now := time.Now()
+	p := newTestPeer("192.0.2.2", gobgpapi.PeerState_ESTABLISHED, map[string]uint64{"ipv4": 10})
+	p.Conf.Description = "transit"
+	p.Conf.LocalAsn = 65000
+	p.Timers = &gobgpapi.Timers{
+		Config: &gobgpapi.TimersConfig{HoldTime: 90},
+		State: &gobgpapi.TimersState{
+			NegotiatedHoldTime: 30,
+			Uptime:             timestamppb.New(now.Add(-time.Minute)),
+			Downtime:           timestamppb.New(now.Add(-time.Hour)),
+		},
+	}
+	info := getPeerInfo(p, now)
+	// The description and the local AS number are taken from the
+	// configuration when the state lacks them.
+	if info.Description != "transit" || info.LocalAsn != 65000 {
+		t.Errorf("expected description %q and local AS 65000, but got %q and %d", "transit", info.Description, info.LocalAsn)
+	}
+	if info.SessionState != "established" {
+		t.Errorf("expected session state %q, but got %q", "established", info.SessionState)
+	}
+	// The downtime of an established session is not reported.
+	if info.Timers.Uptime != 60 || info.Timers.Downtime != 0 {
+		t.Errorf("expected uptime 60 and downtime 0, but got %d and %d", info.Timers.Uptime, info.Timers.Downtime)
+	}
+	if info.Timers.HoldTime != 90 || info.Timers.NegotiatedHoldTime != 30 {
+		t.Errorf("expected hold time 90 and negotiated hold time 30, but got %d and %d", info.Timers.HoldTime, info.Timers.NegotiatedHoldTime)
+	}
+	want := []*PeerFamily{{Family: "ipv4", Enabled: true, Received: 10}}
+	if !reflect.DeepEqual(info.Families, want) {
+		t.Errorf("expected families %v, but got %v", want, info.Families)
+	}
+
+	p.State.SessionState = gobgpapi.PeerState_ACTIVE
+	if info := getPeerInfo(p, now); info.Timers.Uptime != 0 || info.Timers.Downtime != 3600 {
+		t.Errorf("expected uptime 0 and downtime 3600, but got %d and %d", info.Timers.Uptime, info.Timers.Downtime)
+	}
+}
+
+func TestGetAPIErrorCode(t *testing.T) {
+	n := &RouterNode{}
+	if err := n.configureCollectors(Options{Collectors: []string{"router"}}); err != nil {
+		t.Fatalf("failed configuring collectors: %s", err)
+	}
+	if code := getAPIErrorCode(n.refreshAPIData("peers")); code != http.StatusNotFound {
+		t.Errorf("expected status %d w/ disabled collector, but got %d", http.StatusNotFound, code)
+	}
+	if code := getAPIErrorCode(errDataUnavailable); code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d w/ unavailable data, but got %d", http.StatusServiceUnavailable, code)
+	}
+}
+
+func TestAPI(t *testing.T) {
+	client := &stubClient{
+		global: &gobgpapi.Global{Asn: 65000, RouterId: "192.0.2.1"},
+		peers: []*gobgpapi.Peer{
+			newTestPeer("192.0.2.2", gobgpapi.PeerState_ESTABLISHED, map[string]uint64{"ipv4": 10}),
+			newTestPeer("192.0.2.3", gobgpapi.PeerState_ACTIVE, map[string]uint64{"ipv4": 0, "ipv6": 0}),
+		},
+	}
+	e := &Exporter{
+		Node:         newTestRouterNode(t, client),
+		authDisabled: true,
+		logger:       log.NewNopLogger(),
+	}
+	// The router collector is disabled, the data of the other ones is not
+	// gathered until the first query.
+	if err := e.Node.configureCollectors(Options{Collectors: []string{"peers", "rib"}}); err != nil {
+		t.Fatalf("failed configuring collectors: %s", err)
+	}
+
+	type peer struct {
+		Address  string
+		Families []struct{ Family string }
+	}
+	type ribSize struct {
+		Table  string
+		Family string
+	}
+	cases := []struct {
+		name    string
+		handler http.HandlerFunc
+		url     string
+		code    int
+		peers   []peer
+		sizes   []ribSize
+	}{
+		{
+			name:    "peers",
+			handler: e.APIPeers,
+			url:     "/api/v1/peers",
+			code:    http.StatusOK,
+			peers: []peer{
+				{Address: "192.0.2.2", Families: []struct{ Family string }{{"ipv4"}}},
+				{Address: "192.0.2.3", Families: []struct{ Family string }{{"ipv4"}, {"ipv6"}}},
+			},
+		},
+		{
+			name:    "peers filtered by address",
+			handler: e.APIPeers,
+			url:     "/api/v1/peers?peer=192.0.2.3,192.0.2.9",
+			code:    http.StatusOK,
+			peers:   []peer{{Address: "192.0.2.3", Families: []struct{ Family string }{{"ipv4"}, {"ipv6"}}}},
+		},
+		{
+			name:    "peers filtered by family",
+			handler: e.APIPeers,
+			url:     "/api/v1/peers?family=ipv6",
+			code:    http.StatusOK,
+			peers:   []peer{{Address: "192.0.2.3", Families: []struct{ Family string }{{"ipv6"}}}},
+		},
+		{
+			name:    "peers w/ unsupported family",
+			handler: e.APIPeers,
+			url:     "/api/v1/peers?family=bogus",
+			code:    http.StatusBadRequest,
+		},
+		{
+			name:    "rib filtered by table and family",
+			handler: e.APIRib,
+			url:     "/api/v1/rib?table=global&family=ipv4&family=ipv6",
+			code:    http.StatusOK,
+			sizes:   []ribSize{{Table: "global", Family: "ipv4"}, {Table: "global", Family: "ipv6"}},
+		},
+		{
+			name:    "rib w/ unsupported table",
+			handler: e.APIRib,
+			url:     "/api/v1/rib?table=adj_in",
+			code:    http.StatusBadRequest,
+		},
+		{
+			name:    "rib w/ unsupported family",
+			handler: e.APIRib,
+			url:     "/api/v1/rib?family=bogus",
+			code:    http.StatusBadRequest,
+		},
+		{
+			name:    "router w/ disabled collector",
+			handler: e.APIRouter,
+			url:     "/api/v1/router",
+			code:    http.StatusNotFound,
+		},
+	}
+	for _, test := range cases {
+		w := httptest.NewRecorder()
+		test.handler(w, httptest.NewRequest(http.MethodGet, test.url, nil))
+		if w.Code != test.code {
+			t.Errorf("%s: expected status %d, but got %d: %s", test.name, test.code, w.Code, w.Body.String())
+			continue
+		}
+		if test.code != http.StatusOK {
+			var body map[string]string
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil || body["error"] == "" {
+				t.Errorf("%s: expected JSON error, but got %q", test.name, w.Body.String())
+			}
+			continue
+		}
+		if test.peers != nil {
+			var peers []peer
+			if err := json.NewDecoder(w.Body).Decode(&peers); err != nil {
+				t.Fatalf("%s: failed decoding response: %s", test.name, err)
+			}
+			if !reflect.DeepEqual(peers, test.peers) {
+				t.Errorf("%s: expected peers %v, but got %v", test.name, test.peers, peers)
+			}
+		}
+		if test.sizes != nil {
+			var sizes []ribSize
+			if err := json.NewDecoder(w.Body).Decode(&sizes); err != nil {
+				t.Fatalf("%s: failed decoding response: %s", test.name, err)
+			}
+			if !reflect.DeepEqual(sizes, test.sizes) {
+				t.Errorf("%s: expected route table sizes %v, but got %v", test.name, test.sizes, sizes)
+			}
+		}
+	}
+}
+
+func TestAPIUnavailable(t *testing.T) {
+	client := &stubClient{err: status.Error(codes.Unavailable, "connection refused")}
+	e := &Exporter{
+		Node:         newTestRouterNode(t, client),
+		authDisabled: true,
+		logger:       log.NewNopLogger(),
+	}
+	for _, handler := range []http.HandlerFunc{e.APIPeers, e.APIRib, e.APIRouter} {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodGet, "/api/v1", nil))
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status %d, but got %d", http.StatusServiceUnavailable, w.Code)
+		}
+		var body map[string]string
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil || body["error"] != errDataUnavailable.Error() {
+			t.Errorf("expected error %q, but got %q", errDataUnavailable, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"net/http"
+)
+
+// Healthy responds whether the exporter is up. It requires no
+// authentication.
+func (e *Exporter) Healthy(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache, max-age=0, must-revalidate, no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("GoBGP Exporter is Healthy.\n")) //nolint:errcheck
+}
+
+// Ready responds whether the most recent poll of the router succeeded. It
+// never polls the router. It requires no authentication.
+func (e *Exporter) Ready(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache, max-age=0, must-revalidate, no-store")
+	if !e.Node.IsReady() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("GoBGP Exporter is not Ready.\n")) //nolint:errcheck
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("GoBGP Exporter is Ready.\n")) //nolint:errcheck
+}
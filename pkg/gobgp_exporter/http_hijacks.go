@@ -0,0 +1,69 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/go-kit/log/level"
+)
+
+var hijacksTemplate = template.Must(template.ParseFS(templates, "templates/hijacks.html"))
+
+// hijacksPage is the data of the hijacks page.
+type hijacksPage struct {
+	AssetsPath string
+	Violations []*HijackViolation
+}
+
+// wantsJSON returns true when the client asked for JSON, either with the
+// format=json query parameter or with the Accept header.
+func wantsJSON(r *http.Request) bool {
+	if r.URL.Query().Get("format") == "json" {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+// Hijacks returns the details of the violations found by hijack detection,
+// either as an HTML page or as JSON.
+func (e *Exporter) Hijacks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache, max-age=0, must-revalidate, no-store")
+	if !e.authorize(w, r, EndpointHijacks) {
+		return
+	}
+	violations := e.Node.GetHijackViolations()
+	if wantsJSON(r) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(violations) //nolint:errcheck
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := hijacksTemplate.Execute(&buf, &hijacksPage{AssetsPath: AssetsPath, Violations: violations}); err != nil {
+		level.Error(e.logger).Log(
+			"msg", "failed rendering hijacks page",
+			"error", err.Error(),
+		)
+		http.Error(w, "failed rendering hijacks page", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(buf.Bytes()) //nolint:errcheck
+}
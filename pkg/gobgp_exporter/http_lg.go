@@ -0,0 +1,155 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"golang.org/x/net/context"
+)
+
+var lgTemplates = template.Must(template.New("lg").Funcs(templateFuncs).ParseFS(templates, "templates/lg_*.html"))
+
+// lgPage is the data of a looking glass page.
+type lgPage struct {
+	AssetsPath string
+	*LookingGlassResult
+	Peers []*PeerInfo
+}
+
+// authorizeLookingGlass authorizes a looking glass request and limits the
+// rate of the requests of the client.
+func (e *Exporter) authorizeLookingGlass(w http.ResponseWriter, r *http.Request) bool {
+	w.Header().Set("Cache-Control", "no-cache, max-age=0, must-revalidate, no-store")
+	if !e.authorize(w, r, EndpointLG) {
+		return false
+	}
+	client, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		client = r.RemoteAddr
+	}
+	if !e.lgLimiter.allow(client, time.Now()) {
+		level.Warn(e.logger).Log(
+			"msg", "looking glass rate limit exceeded",
+			"client", client,
+		)
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+		return false
+	}
+	return true
+}
+
+// errLookingGlassFailed is returned to the looking glass clients instead of
+// the errors of the queries to GoBGP, which may reveal internal details.
+var errLookingGlassFailed = errors.New("lookup failed")
+
+// writeLookingGlassError writes a looking glass error either as plain text
+// or as JSON.
+func writeLookingGlassError(w http.ResponseWriter, r *http.Request, code int, err error) {
+	if wantsJSON(r) {
+		writeJSONError(w, code, err)
+		return
+	}
+	http.Error(w, err.Error(), code)
+}
+
+// renderLookingGlass writes a looking glass page either as HTML or as JSON.
+func (e *Exporter) renderLookingGlass(w http.ResponseWriter, r *http.Request, name string, page *lgPage, v interface{}) {
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusOK, v)
+		return
+	}
+	var buf bytes.Buffer
+	if err := lgTemplates.ExecuteTemplate(&buf, name, page); err != nil {
+		level.Error(e.logger).Log(
+			"msg", "failed rendering looking glass page",
+			"error", err.Error(),
+		)
+		http.Error(w, "failed rendering looking glass page", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(buf.Bytes()) //nolint:errcheck
+}
+
+// LookingGlassRoute looks up a prefix in the global route table or in the
+// Adj-RIB-In of a peer, and returns the paths with their attributes either
+// as an HTML page or as JSON.
+func (e *Exporter) LookingGlassRoute(w http.ResponseWriter, r *http.Request) {
+	if !e.authorizeLookingGlass(w, r) {
+		return
+	}
+	query := r.URL.Query()
+	q, err := newLookingGlassQuery(query.Get("prefix"), query.Get("table"), query.Get("peer"), query.Get("match"))
+	if err != nil {
+		writeLookingGlassError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx := r.Context()
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(e.timeout)*time.Second)
+		defer cancel()
+	}
+	result, err := e.Node.LookupRoute(ctx, q)
+	if err != nil {
+		if errors.Is(err, errPeerNotFound) {
+			writeLookingGlassError(w, r, http.StatusNotFound, err)
+			return
+		}
+		level.Error(e.logger).Log(
+			"msg", "failed looking glass lookup",
+			"prefix", q.Prefix,
+			"table", q.Table,
+			"peer", q.Peer,
+			"error", err.Error(),
+		)
+		writeLookingGlassError(w, r, http.StatusBadGateway, errLookingGlassFailed)
+		return
+	}
+	e.renderLookingGlass(w, r, "lg_route.html", &lgPage{
+		AssetsPath:         AssetsPath,
+		LookingGlassResult: result,
+	}, result)
+}
+
+// LookingGlassNeighbors returns the BGP neighbors of the router either as an
+// HTML page or as JSON.
+func (e *Exporter) LookingGlassNeighbors(w http.ResponseWriter, r *http.Request) {
+	if !e.authorizeLookingGlass(w, r) {
+		return
+	}
+	peers, err := e.Node.GetPeerInfo(nil, nil)
+	if err != nil {
+		level.Error(e.logger).Log(
+			"msg", "failed looking glass neighbors lookup",
+			"error", err.Error(),
+		)
+		writeLookingGlassError(w, r, getAPIErrorCode(err), errLookingGlassFailed)
+		return
+	}
+	e.renderLookingGlass(w, r, "lg_neighbors.html", &lgPage{
+		AssetsPath: AssetsPath,
+		Peers:      peers,
+	}, peers)
+}
@@ -15,46 +15,139 @@
 package exporter
 
 import (
+	"bytes"
+	"embed"
+	"html/template"
+	"io/fs"
 	"net/http"
-	"strings"
+	"sort"
+	"time"
+
+	"github.com/go-kit/log/level"
+)
+
+// AssetsPath is the path under which the assets of the summary page are
+// served.
+const AssetsPath = "/assets"
+
+// assets are the static files served without authentication, and
+// templates are the sources of the pages, which are not served.
+var (
+	//go:embed assets
+	assets embed.FS
+	//go:embed templates
+	templates embed.FS
 )
 
-// Summary returns the content of the Exporter's default page.
+// templateFuncs are the functions available to the templates of the pages.
+var templateFuncs = template.FuncMap{
+	"duration": func(seconds int64) string {
+		return (time.Duration(seconds) * time.Second).String()
+	},
+}
+
+var dashboardTemplate = template.Must(template.New("dashboard.html").Funcs(templateFuncs).ParseFS(templates, "templates/dashboard.html"))
+
+// dashboardPeer is a row of the peer table of the summary page. The prefix
+// counters are aligned with the address family columns.
+type dashboardPeer struct {
+	*PeerInfo
+	Prefixes []*PeerFamily
+}
+
+// getDashboardPeers returns the rows of the peer table of the summary page,
+// along with the address family columns, i.e. the address families of any
+// peer. The prefix counters of the address families a peer lacks are nil.
+func getDashboardPeers(peers []*PeerInfo) ([]*dashboardPeer, []string) {
+	rows := []*dashboardPeer{}
+	families := []string{}
+	for _, peer := range peers {
+		for _, f := range peer.Families {
+			if !containsString(families, f.Family) {
+				families = append(families, f.Family)
+			}
+		}
+	}
+	sort.Strings(families)
+	for _, peer := range peers {
+		row := &dashboardPeer{PeerInfo: peer}
+		for _, family := range families {
+			var prefixes *PeerFamily
+			for _, f := range peer.Families {
+				if f.Family == family {
+					prefixes = f
+				}
+			}
+			row.Prefixes = append(row.Prefixes, prefixes)
+		}
+		rows = append(rows, row)
+	}
+	return rows, families
+}
+
+// dashboard is the data of the summary page.
+type dashboard struct {
+	Refresh     int64
+	MetricsPath string
+	AssetsPath  string
+	Router      *RouterInfo
+	RouterError error
+	Peers       []*dashboardPeer
+	PeersError  error
+	Families    []string
+	Ribs        []*RibSize
+	RibsError   error
+	Errors      []*RecentError
+}
+
+// Assets returns the handler of the assets, e.g. style sheets, of the
+// summary page.
+func (e *Exporter) Assets() http.Handler {
+	sub, _ := fs.Sub(assets, "assets")
+	return http.StripPrefix(AssetsPath, http.FileServer(http.FS(sub)))
+}
+
+// Summary returns the content of the Exporter's default page: the state of
+// the router, its peers and route tables, and the recent errors.
 func (e *Exporter) Summary(p string, w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Cache-Control", "no-cache, max-age=0, must-revalidate, no-store")
-	token, authorized := e.authorize(r)
-	if !authorized {
-		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	if !e.authorize(w, r, EndpointSummary) {
 		return
 	}
-	var sb strings.Builder
-	sb.WriteString(`<html>`)
-	sb.WriteString(`<head><title>Prometheus Exporter for GoBGP</title></head>`)
-	sb.WriteString(`<body>`)
-	sb.WriteString(`<h1>Prometheus Exporter for GoBGP</h1>`)
-	sb.WriteString(`<table border='1'>`)
-	sb.WriteString(`<tr>`)
-	sb.WriteString(`<th>Node</th>`)
-	sb.WriteString(`<th>Last Result</th>`)
-	sb.WriteString(`<th>Last Scrape</th>`)
-	sb.WriteString(`<th>Metrics</th><tr>`)
 	n := e.Node
-	url := p + `?x-token=` + token
-	sb.WriteString(`<tr>`)
-	sb.WriteString(`<td>` + n.address + `</td>`)
-	switch n.result {
-	case "success":
-		sb.WriteString(`<td style="background-color:lightgreen">` + n.result + `</td>`)
-	case "failure":
-		sb.WriteString(`<td style="background-color:tomato">` + n.result + `</td>`)
-	default:
-		sb.WriteString(`<td style="background-color:lightgray">` + n.result + `</td>`)
+	d := &dashboard{
+		Refresh:     e.GetPollInterval(),
+		MetricsPath: p,
+		AssetsPath:  AssetsPath,
+		Ribs:        []*RibSize{},
+		Errors:      n.GetRecentErrors(),
+	}
+	if d.Refresh < 1 {
+		d.Refresh = 15
+	}
+
+	d.Router, d.RouterError = n.GetRouterState()
+	peers, err := n.GetPeerInfo(nil, nil)
+	d.PeersError = err
+	d.Peers, d.Families = getDashboardPeers(peers)
+
+	sizes, err := n.GetRibSizes(nil, nil)
+	d.RibsError = err
+	for _, s := range sizes {
+		if s.Destinations > 0 || s.Paths > 0 {
+			d.Ribs = append(d.Ribs, s)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := dashboardTemplate.Execute(&buf, d); err != nil {
+		level.Error(e.logger).Log(
+			"msg", "failed rendering summary page",
+			"error", err.Error(),
+		)
+		http.Error(w, "failed rendering summary page", http.StatusInternalServerError)
+		return
 	}
-	sb.WriteString(`<td>` + n.timestamp + `</td>`)
-	sb.WriteString(`<td><a href='` + url + `'>Metrics</a></td>`)
-	sb.WriteString(`</tr>`)
-	sb.WriteString(`</table>`)
-	sb.WriteString(`</body>`)
-	sb.WriteString(`</html>`)
-	w.Write([]byte(sb.String())) //nolint:errcheck
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(buf.Bytes()) //nolint:errcheck
 }
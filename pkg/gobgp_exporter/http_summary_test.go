@@ -0,0 +1,115 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	gobgpapi "github.com/osrg/gobgp/v3/api"
+)
+
+func TestGetDashboardPeers(t *testing.T) {
+	ipv4 := &PeerFamily{Family: "ipv4", Received: 10}
+	ipv6 := &PeerFamily{Family: "ipv6", Received: 20}
+	evpn := &PeerFamily{Family: "evpn", Received: 30}
+	peers := []*PeerInfo{
+		{Address: "192.0.2.2", Families: []*PeerFamily{ipv6, ipv4}},
+		{Address: "192.0.2.3", Families: []*PeerFamily{ipv4}},
+		{Address: "192.0.2.4", Families: []*PeerFamily{evpn}},
+	}
+	rows, families := getDashboardPeers(peers)
+	if want := []string{"evpn", "ipv4", "ipv6"}; !reflect.DeepEqual(families, want) {
+		t.Errorf("expected families %v, but got %v", want, families)
+	}
+	// The prefix counters are aligned with the address family columns,
+	// regardless of the order of the address families of a peer.
+	want := [][]*PeerFamily{
+		{nil, ipv4, ipv6},
+		{nil, ipv4, nil},
+		{evpn, nil, nil},
+	}
+	for i, row := range rows {
+		if !reflect.DeepEqual(row.Prefixes, want[i]) {
+			t.Errorf("expected prefixes %v of %s, but got %v", want[i], row.Address, row.Prefixes)
+		}
+	}
+}
+
+func TestSummary(t *testing.T) {
+	peer := newTestPeer("192.0.2.2", gobgpapi.PeerState_ESTABLISHED, map[string]uint64{"ipv4": 10})
+	peer.Conf.Description = `<script>alert("peer")</script>`
+	client := &stubClient{
+		global: &gobgpapi.Global{Asn: 65000, RouterId: "192.0.2.1"},
+		peers: []*gobgpapi.Peer{
+			peer,
+			newTestPeer("192.0.2.3", gobgpapi.PeerState_ACTIVE, map[string]uint64{"ipv6": 0}),
+		},
+	}
+	e := &Exporter{
+		Node:         newTestRouterNode(t, client),
+		authDisabled: true,
+		logger:       log.NewNopLogger(),
+	}
+	e.Node.recordError("failed query <b>gobgp</b> server", errors.New("<img src=x>"))
+
+	w := httptest.NewRecorder()
+	e.Summary("/metrics", w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, but got %d", http.StatusOK, w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("expected content type %q, but got %q", "text/html; charset=utf-8", ct)
+	}
+	body := w.Body.String()
+	for _, s := range []string{
+		`&lt;script&gt;alert(&#34;peer&#34;)&lt;/script&gt;`,
+		`failed query &lt;b&gt;gobgp&lt;/b&gt; server`,
+		`&lt;img src=x&gt;`,
+		`<th>ipv4<br>received/accepted</th><th>ipv6<br>received/accepted</th>`,
+		`<td>10/0</td><td>-</td>`,
+		`<td>-</td><td>0/0</td>`,
+	} {
+		if !strings.Contains(body, s) {
+			t.Errorf("expected %q in summary page, but got none", s)
+		}
+	}
+	for _, s := range []string{`<script>alert`, `<b>gobgp`, `<img src=x>`} {
+		if strings.Contains(body, s) {
+			t.Errorf("expected %q escaped in summary page, but got it verbatim", s)
+		}
+	}
+}
+
+func TestRecordError(t *testing.T) {
+	n := &RouterNode{}
+	for i := 0; i < maxRecentErrors+5; i++ {
+		n.recordError(fmt.Sprintf("error %d", i), errors.New("failed"))
+	}
+	errs := n.GetRecentErrors()
+	if len(errs) != maxRecentErrors {
+		t.Fatalf("expected %d recent errors, but got %d", maxRecentErrors, len(errs))
+	}
+	// The most recent errors are kept, the most recent first.
+	if first, last := errs[0].Message, errs[len(errs)-1].Message; first != "error 24" || last != "error 5" {
+		t.Errorf("expected errors from %q to %q, but got from %q to %q", "error 24", "error 5", first, last)
+	}
+}
@@ -0,0 +1,342 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	gobgpapi "github.com/osrg/gobgp/v3/api"
+	"github.com/osrg/gobgp/v3/pkg/packet/bgp"
+	"golang.org/x/net/context"
+)
+
+// errPeerNotFound is returned by the looking glass lookups of the Adj-RIB-In
+// of unknown peers.
+var errPeerNotFound = errors.New("peer not found")
+
+// maxLookingGlassDestinations is the maximum number of destinations
+// returned by a looking glass lookup.
+const maxLookingGlassDestinations = 100
+
+// lookingGlassTables are the route tables available to looking glass
+// lookups.
+var lookingGlassTables = map[string]gobgpapi.TableType{
+	"global": gobgpapi.TableType_GLOBAL,
+	"adj-in": gobgpapi.TableType_ADJ_IN,
+}
+
+// lookingGlassMatches are the types of prefix lookups.
+var lookingGlassMatches = map[string]gobgpapi.TableLookupPrefix_Type{
+	"exact":   gobgpapi.TableLookupPrefix_EXACT,
+	"longer":  gobgpapi.TableLookupPrefix_LONGER,
+	"shorter": gobgpapi.TableLookupPrefix_SHORTER,
+}
+
+// LookingGlassQuery is a looking glass lookup of a prefix. Only unicast
+// prefixes are looked up, so that the address family is either ipv4 or
+// ipv6, derived from the prefix.
+type LookingGlassQuery struct {
+	Prefix string `json:"prefix"`
+	Family string `json:"family"`
+	Table  string `json:"table"`
+	Peer   string `json:"peer,omitempty"`
+	Match  string `json:"match"`
+}
+
+// LookingGlassPath is a path with its attributes.
+type LookingGlassPath struct {
+	Peer        string   `json:"peer"`
+	NextHop     string   `json:"next_hop"`
+	AsPath      string   `json:"as_path"`
+	Origin      string   `json:"origin"`
+	Med         *uint32  `json:"med,omitempty"`
+	LocalPref   *uint32  `json:"local_pref,omitempty"`
+	Communities []string `json:"communities"`
+	Validation  string   `json:"validation"`
+	Best        bool     `json:"best"`
+	Filtered    bool     `json:"filtered"`
+	Stale       bool     `json:"stale"`
+	Age         int64    `json:"age"`
+}
+
+// LookingGlassRoute is a destination with its paths.
+type LookingGlassRoute struct {
+	Prefix string              `json:"prefix"`
+	Paths  []*LookingGlassPath `json:"paths"`
+}
+
+// LookingGlassResult is the result of a looking glass lookup. The result is
+// truncated when the lookup matches too many destinations.
+type LookingGlassResult struct {
+	Query     *LookingGlassQuery   `json:"query"`
+	Routes    []*LookingGlassRoute `json:"routes"`
+	Truncated bool                 `json:"truncated"`
+}
+
+// newLookingGlassQuery validates the parameters of a looking glass lookup.
+// An IP address without prefix length is looked up as a host route. The
+// prefixes of the address families other than IPv4 and IPv6 unicast, e.g.
+// EVPN or flowspec, are not supported.
+func newLookingGlassQuery(prefix, table, peer, match string) (*LookingGlassQuery, error) {
+	if prefix == "" {
+		return nil, fmt.Errorf("prefix is required")
+	}
+	if !strings.Contains(prefix, "/") {
+		ip := net.ParseIP(prefix)
+		switch {
+		case ip == nil:
+			return nil, fmt.Errorf("invalid prefix %q", prefix)
+		case ip.To4() != nil:
+			prefix += "/32"
+		default:
+			prefix += "/128"
+		}
+	}
+	prefix, family, err := parsePrefix(prefix)
+	if err != nil {
+		return nil, err
+	}
+	q := &LookingGlassQuery{
+		Prefix: prefix,
+		Family: family,
+		Table:  table,
+		Match:  match,
+	}
+	if q.Table == "" {
+		q.Table = "global"
+	}
+	if _, exists := lookingGlassTables[q.Table]; !exists {
+		return nil, fmt.Errorf("unsupported route table %q", table)
+	}
+	if q.Match == "" {
+		q.Match = "exact"
+	}
+	if _, exists := lookingGlassMatches[q.Match]; !exists {
+		return nil, fmt.Errorf("unsupported match %q", match)
+	}
+	if peer != "" {
+		ip := net.ParseIP(peer)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid peer %q", peer)
+		}
+		q.Peer = ip.String()
+	}
+	if q.Table == "adj-in" && q.Peer == "" {
+		return nil, fmt.Errorf("peer is required for adj-in route table")
+	}
+	return q, nil
+}
+
+// getOriginName returns the name of the ORIGIN attribute value.
+func getOriginName(origin uint8) string {
+	switch origin {
+	case bgp.BGP_ORIGIN_ATTR_TYPE_IGP:
+		return "igp"
+	case bgp.BGP_ORIGIN_ATTR_TYPE_EGP:
+		return "egp"
+	default:
+		return "incomplete"
+	}
+}
+
+// getValidationName returns the RPKI validation state of a path, e.g.
+// valid, invalid, or not_found.
+func getValidationName(p *gobgpapi.Path) string {
+	return strings.ToLower(strings.TrimPrefix(p.GetValidation().GetState().String(), "STATE_"))
+}
+
+// getLookingGlassPath returns the attributes of the i-th path of a
+// destination.
+func (d *ribDestination) getLookingGlassPath(i int, now time.Time) *LookingGlassPath {
+	p := d.Paths[i]
+	attrs := d.getPathAttributes(i)
+	lp := &LookingGlassPath{
+		Peer:        getPathPeer(p),
+		NextHop:     getNextHop(attrs),
+		Communities: getCommunities(attrs),
+		Validation:  getValidationName(p),
+		Best:        p.GetBest(),
+		Filtered:    p.GetFiltered(),
+		Stale:       p.GetStale(),
+	}
+	if age := p.GetAge(); age != nil && age.GetSeconds() > 0 {
+		lp.Age = int64(now.Sub(age.AsTime()).Seconds())
+	}
+	for _, attr := range attrs {
+		switch a := attr.(type) {
+		case *bgp.PathAttributeAsPath:
+			lp.AsPath = bgp.AsPathString(a)
+		case *bgp.PathAttributeOrigin:
+			lp.Origin = getOriginName(a.Value)
+		case *bgp.PathAttributeMultiExitDisc:
+			med := a.Value
+			lp.Med = &med
+		case *bgp.PathAttributeLocalPref:
+			localPref := a.Value
+			lp.LocalPref = &localPref
+		}
+	}
+	return lp
+}
+
+// LookupRoute looks up a prefix in a route table. The Adj-RIB-In lookups
+// are limited to the configured peers, and the global route table lookups
+// optionally return the paths of a single peer only.
+func (n *RouterNode) LookupRoute(ctx context.Context, q *LookingGlassQuery) (*LookingGlassResult, error) {
+	name := ""
+	if q.Table == "adj-in" {
+		peers, err := n.listPeers(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range peers {
+			if p.GetState().GetNeighborAddress() == q.Peer {
+				name = q.Peer
+			}
+		}
+		if name == "" {
+			return nil, fmt.Errorf("%w: %s", errPeerNotFound, q.Peer)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := n.client.ListPath(ctx, &gobgpapi.ListPathRequest{
+		TableType: lookingGlassTables[q.Table],
+		Name:      name,
+		Family:    addressFamilies[q.Family],
+		Prefixes: []*gobgpapi.TableLookupPrefix{
+			{
+				Prefix: q.Prefix,
+				Type:   lookingGlassMatches[q.Match],
+			},
+		},
+		EnableFiltered: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := &LookingGlassResult{
+		Query:  q,
+		Routes: []*LookingGlassRoute{},
+	}
+	for {
+		r, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if !result.addRoute(&ribDestination{Destination: r.GetDestination()}, now) {
+			break
+		}
+	}
+	return result, nil
+}
+
+// addRoute adds the paths of a destination matching the peer of the query
+// to the result. It returns false, and marks the result truncated, when
+// the result already has the maximum number of destinations.
+func (r *LookingGlassResult) addRoute(d *ribDestination, now time.Time) bool {
+	route := &LookingGlassRoute{
+		Prefix: d.GetPrefix(),
+		Paths:  []*LookingGlassPath{},
+	}
+	for i, p := range d.GetPaths() {
+		if r.Query.Peer != "" && getPathPeer(p) != r.Query.Peer {
+			continue
+		}
+		route.Paths = append(route.Paths, d.getLookingGlassPath(i, now))
+	}
+	if len(route.Paths) == 0 {
+		return true
+	}
+	if len(r.Routes) >= maxLookingGlassDestinations {
+		r.Truncated = true
+		return false
+	}
+	r.Routes = append(r.Routes, route)
+	return true
+}
+
+// rateLimiter is a token bucket rate limiter on per client basis.
+type rateLimiter struct {
+	sync.Mutex
+	rate    float64
+	burst   float64
+	clients map[string]*rateBucket
+}
+
+type rateBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// maxRateLimiterClients is the number of tracked clients triggering the
+// removal of the buckets of idle clients.
+const maxRateLimiterClients = 10000
+
+// newRateLimiter returns a rate limiter allowing rate requests per second
+// with bursts of up to burst requests. The rate limiter allows all requests
+// when the rate is not positive.
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		clients: make(map[string]*rateBucket),
+	}
+}
+
+// allow returns true when a request of a client is within the rate limit.
+func (l *rateLimiter) allow(client string, now time.Time) bool {
+	if l.rate <= 0 {
+		return true
+	}
+	l.Lock()
+	defer l.Unlock()
+	if len(l.clients) >= maxRateLimiterClients {
+		for k, b := range l.clients {
+			if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+				delete(l.clients, k)
+			}
+		}
+	}
+	b, exists := l.clients[client]
+	if !exists {
+		b = &rateBucket{tokens: l.burst, last: now}
+		l.clients[client] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
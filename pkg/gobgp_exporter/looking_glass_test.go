@@ -0,0 +1,171 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	gobgpapi "github.com/osrg/gobgp/v3/api"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNewLookingGlassQuery(t *testing.T) {
+	cases := []struct {
+		prefix string
+		table  string
+		peer   string
+		match  string
+		want   string
+		ok     bool
+	}{
+		{prefix: "192.0.2.1/24", want: "192.0.2.0/24 ipv4 global exact", ok: true},
+		{prefix: "192.0.2.1", match: "shorter", want: "192.0.2.1/32 ipv4 global shorter", ok: true},
+		{prefix: "2001:db8::/32", table: "adj-in", peer: "192.0.2.1", want: "2001:db8::/32 ipv6 adj-in exact", ok: true},
+		{prefix: "192.0.2.0/24", table: "adj-in", ok: false},
+		{prefix: "192.0.2.0/24", table: "adj-out", ok: false},
+		{prefix: "192.0.2.0/24", match: "any", ok: false},
+		{prefix: "192.0.2.0/24", peer: "router1", ok: false},
+		{prefix: "example.com", ok: false},
+		{prefix: "", ok: false},
+	}
+	for _, test := range cases {
+		q, err := newLookingGlassQuery(test.prefix, test.table, test.peer, test.match)
+		if !test.ok {
+			if err == nil {
+				t.Errorf("expected error w/ %q, but got none", test.prefix)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("expected no error w/ %q, but got %q", test.prefix, err)
+			continue
+		}
+		if got := q.Prefix + " " + q.Family + " " + q.Table + " " + q.Match; got != test.want {
+			t.Errorf("expected %q w/ %q, but got %q", test.want, test.prefix, got)
+		}
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(1, 2)
+	now := time.Now()
+	for i, want := range []bool{true, true, false} {
+		if got := l.allow("192.0.2.1", now); got != want {
+			t.Errorf("expected request %d to be allowed %t, but got %t", i, want, got)
+		}
+	}
+	if !l.allow("192.0.2.2", now) {
+		t.Errorf("expected the request of another client to be allowed")
+	}
+	if !l.allow("192.0.2.1", now.Add(time.Second)) {
+		t.Errorf("expected the request to be allowed after a second")
+	}
+}
+
+func TestLookingGlassTruncated(t *testing.T) {
+	destination := func(i int, peer string) *ribDestination {
+		return &ribDestination{Destination: &gobgpapi.Destination{
+			Prefix: "10.0." + strconv.Itoa(i) + ".0/24",
+			Paths:  []*gobgpapi.Path{{NeighborIp: peer}},
+		}}
+	}
+	cases := []struct {
+		name      string
+		peer      string
+		last      string
+		truncated bool
+	}{
+		{name: "last destination matching", last: "192.0.2.1", truncated: true},
+		{name: "last destination of other peer", peer: "192.0.2.1", last: "192.0.2.2", truncated: false},
+	}
+	for _, test := range cases {
+		result := &LookingGlassResult{
+			Query:  &LookingGlassQuery{Peer: test.peer},
+			Routes: []*LookingGlassRoute{},
+		}
+		for i := 0; i < maxLookingGlassDestinations; i++ {
+			result.addRoute(destination(i, "192.0.2.1"), time.Now())
+		}
+		result.addRoute(destination(maxLookingGlassDestinations, test.last), time.Now())
+		if len(result.Routes) != maxLookingGlassDestinations || result.Truncated != test.truncated {
+			t.Errorf("%s: expected %d routes (truncated %t), but got %d (truncated %t)",
+				test.name, maxLookingGlassDestinations, test.truncated, len(result.Routes), result.Truncated)
+		}
+	}
+}
+
+func TestLookingGlassRouteErrors(t *testing.T) {
+	client := &stubClient{
+		peers: []*gobgpapi.Peer{{State: &gobgpapi.PeerState{NeighborAddress: "192.0.2.2"}}},
+	}
+	e := &Exporter{
+		Node:         newTestRouterNode(t, client),
+		authDisabled: true,
+		lgLimiter:    newRateLimiter(0, 0),
+		logger:       log.NewNopLogger(),
+	}
+	cases := []struct {
+		name string
+		url  string
+		err  error
+		code int
+		body string
+	}{
+		{name: "invalid prefix", url: "/lg/route?prefix=bogus", code: http.StatusBadRequest, body: "invalid prefix"},
+		{name: "unknown peer", url: "/lg/route?prefix=192.0.2.0/24&table=adj-in&peer=192.0.2.9", code: http.StatusNotFound, body: "192.0.2.9"},
+		{
+			name: "gobgp error",
+			url:  "/lg/route?prefix=192.0.2.0/24",
+			err:  status.Error(codes.Unavailable, "connection error: dial tcp 10.0.0.1:50051"),
+			code: http.StatusBadGateway,
+			body: "lookup failed",
+		},
+	}
+	for _, test := range cases {
+		client.err = test.err
+		w := httptest.NewRecorder()
+		e.LookingGlassRoute(w, httptest.NewRequest(http.MethodGet, test.url, nil))
+		if w.Code != test.code {
+			t.Errorf("%s: expected status %d, but got %d", test.name, test.code, w.Code)
+		}
+		if body := w.Body.String(); !strings.Contains(body, test.body) || strings.Contains(body, "dial tcp") {
+			t.Errorf("%s: expected body with %q, but got %q", test.name, test.body, body)
+		}
+	}
+}
+
+func TestLookupRouteContext(t *testing.T) {
+	n := newTestRouterNode(t, &stubClient{
+		peers: []*gobgpapi.Peer{{State: &gobgpapi.PeerState{NeighborAddress: "192.0.2.2"}}},
+	})
+	q, err := newLookingGlassQuery("192.0.2.0/24", "adj-in", "192.0.2.2", "")
+	if err != nil {
+		t.Fatalf("failed building query: %s", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := n.LookupRoute(ctx, q); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected canceled lookup of peers, but got %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"time"
+)
+
+// maxRecentErrors is the maximum number of errors kept for the summary
+// page.
+const maxRecentErrors = 20
+
+// RecentError is an error of a query to a router.
+type RecentError struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+	Error   string    `json:"error"`
+}
+
+// recordError keeps an error for the summary page. Only the most recent
+// errors are kept.
+func (n *RouterNode) recordError(msg string, err error) {
+	n.errorsLocker.Lock()
+	defer n.errorsLocker.Unlock()
+	n.recentErrors = append(n.recentErrors, &RecentError{
+		Time:    time.Now(),
+		Message: msg,
+		Error:   err.Error(),
+	})
+	if len(n.recentErrors) > maxRecentErrors {
+		n.recentErrors = n.recentErrors[len(n.recentErrors)-maxRecentErrors:]
+	}
+}
+
+// GetRecentErrors returns the recent errors of the queries to the router,
+// the most recent first.
+func (n *RouterNode) GetRecentErrors() []*RecentError {
+	n.errorsLocker.RLock()
+	defer n.errorsLocker.RUnlock()
+	errors := make([]*RecentError, 0, len(n.recentErrors))
+	for i := len(n.recentErrors) - 1; i >= 0; i-- {
+		errors = append(errors, n.recentErrors[i])
+	}
+	return errors
+}
@@ -0,0 +1,225 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"strconv"
+
+	gobgpapi "github.com/osrg/gobgp/v3/api"
+	"github.com/osrg/gobgp/v3/pkg/apiutil"
+	"github.com/osrg/gobgp/v3/pkg/packet/bgp"
+)
+
+// getPathAttributes returns the decoded attributes of the i-th path of the
+// destination. The attributes are decoded once and shared by all analyzers.
+// The attributes GoBGP's apiutil is unable to decode are skipped.
+func (d *ribDestination) getPathAttributes(i int) []bgp.PathAttributeInterface {
+	if d.attributes == nil {
+		d.attributes = make([][]bgp.PathAttributeInterface, len(d.GetPaths()))
+	}
+	if d.attributes[i] == nil {
+		attrs := make([]bgp.PathAttributeInterface, 0, len(d.Paths[i].GetPattrs()))
+		for _, an := range d.Paths[i].GetPattrs() {
+			attr, err := apiutil.UnmarshalAttribute(an)
+			if err != nil {
+				continue
+			}
+			attrs = append(attrs, attr)
+		}
+		d.attributes[i] = attrs
+	}
+	return d.attributes[i]
+}
+
+// getBestPath returns the index of the best path of the destination. The
+// tables without best path selection, e.g. Adj-RIB-In, have the first path
+// returned instead. It returns -1 when the destination has no paths.
+func (d *ribDestination) getBestPath() int {
+	for i, p := range d.GetPaths() {
+		if p.GetBest() {
+			return i
+		}
+	}
+	if len(d.GetPaths()) > 0 {
+		return 0
+	}
+	return -1
+}
+
+// getAsPath returns the AS_PATH attribute of a path.
+func getAsPath(attrs []bgp.PathAttributeInterface) *bgp.PathAttributeAsPath {
+	for _, attr := range attrs {
+		if a, ok := attr.(*bgp.PathAttributeAsPath); ok {
+			return a
+		}
+	}
+	return nil
+}
+
+// getAsPathLength returns the length of AS path used in best path selection,
+// i.e. an AS_SET counts as one AS and confederation segments are ignored.
+func getAsPathLength(a *bgp.PathAttributeAsPath) int {
+	if a == nil {
+		return 0
+	}
+	length := 0
+	for _, param := range a.Value {
+		length += param.ASLen()
+	}
+	return length
+}
+
+// getOriginAS returns the AS originating the path, i.e. the last AS of
+// the AS path. The locally originated paths have the AS number of the
+// router. The origin is ambiguous when the path ends with an AS_SET.
+func getOriginAS(a *bgp.PathAttributeAsPath, localAS uint32) (uint32, bool) {
+	origin := localAS
+	ambiguous := false
+	if a == nil {
+		return origin, true
+	}
+	for _, param := range a.Value {
+		asns := param.GetAS()
+		if len(asns) == 0 {
+			continue
+		}
+		switch param.GetType() {
+		case bgp.BGP_ASPATH_ATTR_TYPE_SEQ:
+			origin = asns[len(asns)-1]
+			ambiguous = false
+		case bgp.BGP_ASPATH_ATTR_TYPE_SET:
+			ambiguous = true
+		}
+	}
+	return origin, !ambiguous
+}
+
+// getAsPathASNs returns all AS numbers found in the AS path.
+func getAsPathASNs(a *bgp.PathAttributeAsPath) []uint32 {
+	asns := []uint32{}
+	if a == nil {
+		return asns
+	}
+	for _, param := range a.Value {
+		asns = append(asns, param.GetAS()...)
+	}
+	return asns
+}
+
+// hasAsSet returns true when the AS path contains an AS_SET.
+func hasAsSet(a *bgp.PathAttributeAsPath) bool {
+	if a == nil {
+		return false
+	}
+	for _, param := range a.Value {
+		if param.GetType() == bgp.BGP_ASPATH_ATTR_TYPE_SET {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPrepending returns true when an AS appears more than once in a row in
+// the AS path.
+func hasPrepending(a *bgp.PathAttributeAsPath) bool {
+	if a == nil {
+		return false
+	}
+	for _, param := range a.Value {
+		if param.GetType() != bgp.BGP_ASPATH_ATTR_TYPE_SEQ {
+			continue
+		}
+		asns := param.GetAS()
+		for i := 1; i < len(asns); i++ {
+			if asns[i] == asns[i-1] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getPathPeer returns the address of the peer the path was received from.
+// The locally originated paths have no peer address.
+func getPathPeer(p *gobgpapi.Path) string {
+	switch s := p.GetNeighborIp(); s {
+	case "<nil>", "0.0.0.0", "::":
+		return ""
+	default:
+		return s
+	}
+}
+
+// getStandardCommunityName returns the name of a standard community, e.g.
+// 65000:666, or no-export for the well-known communities.
+func getStandardCommunityName(c uint32) string {
+	if name, exists := bgp.WellKnownCommunityNameMap[bgp.WellKnownCommunity(c)]; exists {
+		return name
+	}
+	return strconv.FormatUint(uint64(c>>16), 10) + ":" + strconv.FormatUint(uint64(c&0xffff), 10)
+}
+
+// getExtendedCommunityName returns the name of an extended community, e.g.
+// rt:65000:100 for route targets, soo:65000:100 for route origins.
+func getExtendedCommunityName(c bgp.ExtendedCommunityInterface) string {
+	_, subtype := c.GetTypes()
+	switch subtype {
+	case bgp.EC_SUBTYPE_ROUTE_TARGET:
+		return "rt:" + c.String()
+	case bgp.EC_SUBTYPE_ROUTE_ORIGIN:
+		return "soo:" + c.String()
+	default:
+		return c.String()
+	}
+}
+
+// getCommunities returns the names of standard, extended, and large
+// communities of a path.
+func getCommunities(attrs []bgp.PathAttributeInterface) []string {
+	communities := []string{}
+	for _, attr := range attrs {
+		switch a := attr.(type) {
+		case *bgp.PathAttributeCommunities:
+			for _, c := range a.Value {
+				communities = append(communities, getStandardCommunityName(c))
+			}
+		case *bgp.PathAttributeExtendedCommunities:
+			for _, c := range a.Value {
+				communities = append(communities, getExtendedCommunityName(c))
+			}
+		case *bgp.PathAttributeLargeCommunities:
+			for _, c := range a.Values {
+				communities = append(communities, c.String())
+			}
+		}
+	}
+	return communities
+}
+
+// getNextHop returns the next hop of a path, taken either from NEXT_HOP or
+// MP_REACH_NLRI attribute.
+func getNextHop(attrs []bgp.PathAttributeInterface) string {
+	for _, attr := range attrs {
+		switch a := attr.(type) {
+		case *bgp.PathAttributeNextHop:
+			return a.Value.String()
+		case *bgp.PathAttributeMpReachNLRI:
+			if a.Nexthop != nil {
+				return a.Nexthop.String()
+			}
+		}
+	}
+	return ""
+}
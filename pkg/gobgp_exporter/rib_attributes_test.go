@@ -0,0 +1,84 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"testing"
+
+	"github.com/osrg/gobgp/v3/pkg/packet/bgp"
+)
+
+func TestAsPathAttributes(t *testing.T) {
+	seq := func(asns ...uint32) bgp.AsPathParamInterface {
+		return bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_SEQ, asns)
+	}
+	set := func(asns ...uint32) bgp.AsPathParamInterface {
+		return bgp.NewAs4PathParam(bgp.BGP_ASPATH_ATTR_TYPE_SET, asns)
+	}
+
+	cases := []struct {
+		name      string
+		asPath    *bgp.PathAttributeAsPath
+		length    int
+		origin    uint32
+		ok        bool
+		asSet     bool
+		prepended bool
+	}{
+		{
+			name:   "locally originated",
+			asPath: bgp.NewPathAttributeAsPath(nil),
+			origin: 65000,
+			ok:     true,
+		},
+		{
+			name:   "sequence",
+			asPath: bgp.NewPathAttributeAsPath([]bgp.AsPathParamInterface{seq(65001, 65002, 65003)}),
+			length: 3,
+			origin: 65003,
+			ok:     true,
+		},
+		{
+			name:      "prepended",
+			asPath:    bgp.NewPathAttributeAsPath([]bgp.AsPathParamInterface{seq(65001, 65001, 65001, 65003)}),
+			length:    4,
+			origin:    65003,
+			ok:        true,
+			prepended: true,
+		},
+		{
+			name:   "aggregated with as set",
+			asPath: bgp.NewPathAttributeAsPath([]bgp.AsPathParamInterface{seq(65001), set(65002, 65003)}),
+			length: 2,
+			asSet:  true,
+		},
+	}
+
+	for _, test := range cases {
+		if length := getAsPathLength(test.asPath); length != test.length {
+			t.Errorf("%s: expected AS path length %d, but got %d", test.name, test.length, length)
+		}
+		origin, ok := getOriginAS(test.asPath, 65000)
+		if ok != test.ok || (ok && origin != test.origin) {
+			t.Errorf("%s: expected origin AS %d (%t), but got %d (%t)", test.name, test.origin, test.ok, origin, ok)
+		}
+		if asSet := hasAsSet(test.asPath); asSet != test.asSet {
+			t.Errorf("%s: expected AS_SET %t, but got %t", test.name, test.asSet, asSet)
+		}
+		if prepended := hasPrepending(test.asPath); prepended != test.prepended {
+			t.Errorf("%s: expected prepending %t, but got %t", test.name, test.prepended, prepended)
+		}
+	}
+}
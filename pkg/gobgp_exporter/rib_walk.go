@@ -0,0 +1,392 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/go-kit/log/level"
+	gobgpapi "github.com/osrg/gobgp/v3/api"
+	"github.com/osrg/gobgp/v3/pkg/packet/bgp"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var ribTableTypes = map[string]gobgpapi.TableType{
+	"global":  gobgpapi.TableType_GLOBAL,
+	"local":   gobgpapi.TableType_LOCAL,
+	"adj_in":  gobgpapi.TableType_ADJ_IN,
+	"adj_out": gobgpapi.TableType_ADJ_OUT,
+}
+
+// ribTarget is a single route table walked with ListPath.
+type ribTarget struct {
+	table     string
+	tableType gobgpapi.TableType
+	family    string
+	peer      string
+	// neighbor is the configuration and the state of the peer of the
+	// Adj-RIB-In and Adj-RIB-Out tables.
+	neighbor *gobgpapi.Peer
+	// filtered requests marking the Adj-RIB-In paths rejected by policy.
+	filtered bool
+}
+
+// String returns the route table, the address family, and the peer, if any,
+// of a route table walk.
+func (t *ribTarget) String() string {
+	if t.peer == "" {
+		return t.table + " " + t.family
+	}
+	return t.table + " " + t.family + " " + t.peer
+}
+
+// labels returns the values of the route_table, address_family, and peer
+// labels of the metrics produced for the route table.
+func (t *ribTarget) labels() []string {
+	return []string{t.table, t.family, t.peer}
+}
+
+// ribDestination is a destination received while walking a route table.
+type ribDestination struct {
+	*gobgpapi.Destination
+	attributes [][]bgp.PathAttributeInterface
+}
+
+// ribAnalyzer accumulates statistics about the destinations of a single
+// route table.
+type ribAnalyzer interface {
+	// observe is called for each destination of the route table.
+	observe(d *ribDestination)
+	// metrics returns the metrics built once the walk is complete.
+	metrics() []prometheus.Metric
+}
+
+// getTopCounts returns the top N keys by count, the keys of equal counts
+// ordered by less, along with the sum of the counts of the rest of the keys.
+// The rest of the keys are reported as "other" by the analyzers to keep the
+// cardinality of their metrics bounded.
+func getTopCounts[K comparable](counts map[K]uint64, topN int, less func(a, b K) bool) ([]K, uint64) {
+	keys := make([]K, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return less(keys[i], keys[j])
+	})
+	if topN > len(keys) {
+		topN = len(keys)
+	}
+	var other uint64
+	for _, k := range keys[topN:] {
+		other += counts[k]
+	}
+	return keys[:topN], other
+}
+
+// ribCollector is an optional collector that analyzes the contents of
+// route tables.
+type ribCollector struct {
+	help        string
+	newAnalyzer func(n *RouterNode, t *ribTarget) ribAnalyzer
+}
+
+var ribCollectors = map[string]*ribCollector{
+	"prefix_length": {
+		help:        "Collect the distribution of prefix lengths in route tables.",
+		newAnalyzer: newPrefixLengthAnalyzer,
+	},
+	"as_path": {
+		help:        "Collect the statistics of AS paths and origin AS numbers in route tables.",
+		newAnalyzer: newAsPathAnalyzer,
+	},
+	"community": {
+		help:        "Collect the number of paths carrying the communities from the watch list.",
+		newAnalyzer: newCommunityAnalyzer,
+	},
+	"bogon": {
+		help:        "Collect the number of paths to bogon prefixes and with bogon AS numbers in AS path.",
+		newAnalyzer: newBogonAnalyzer,
+	},
+	"churn": {
+		help:        "Collect the number of added, withdrawn, and best path changed prefixes between polls.",
+		newAnalyzer: newChurnAnalyzer,
+	},
+	"next_hop": {
+		help:        "Collect the inventory of next hops and the number of paths with invalid next hop.",
+		newAnalyzer: newNextHopAnalyzer,
+	},
+	"evpn": {
+		help:        "Collect the number of EVPN routes by route type, and optionally by route distinguisher and VNI.",
+		newAnalyzer: newEvpnAnalyzer,
+	},
+	"flowspec": {
+		help:        "Collect the number of FlowSpec rules by action and originating peer, and the age of the oldest rule.",
+		newAnalyzer: newFlowspecAnalyzer,
+	},
+	"route_age": {
+		help:        "Collect the distribution of path age and the number of stale paths in route tables.",
+		newAnalyzer: newRouteAgeAnalyzer,
+	},
+	"vpn": {
+		help:        "Collect the number of L3VPN routes on per route distinguisher and per route target basis.",
+		newAnalyzer: newVpnAnalyzer,
+	},
+	"multipath": {
+		help:        "Collect the distribution of the number of paths per destination and the number of ECMP destinations.",
+		newAnalyzer: newMultipathAnalyzer,
+	},
+	"adj_in": {
+		help:        "Collect the number of received, accepted, rejected by import policy, and invalid paths in Adj-RIB-In.",
+		newAnalyzer: newAdjInAnalyzer,
+	},
+}
+
+func containsString(l []string, s string) bool {
+	for _, e := range l {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// GetRibCollectors returns the names of the optional route table collectors.
+func GetRibCollectors() []string {
+	names := []string{}
+	for name := range ribCollectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetRibCollectorHelp returns the description of an optional route table
+// collector.
+func GetRibCollectorHelp(name string) string {
+	if c, exists := ribCollectors[name]; exists {
+		return c.help
+	}
+	return ""
+}
+
+// configureRib sets the route tables and address families used for route
+// table walks.
+func (n *RouterNode) configureRib(opts Options) error {
+	for _, s := range opts.RibTables {
+		if _, exists := ribTableTypes[s]; !exists {
+			return fmt.Errorf("unsupported route table %q", s)
+		}
+	}
+	for _, s := range opts.RibFamilies {
+		if _, exists := addressFamilies[s]; !exists {
+			return fmt.Errorf("unsupported address family %q", s)
+		}
+	}
+	for _, s := range opts.RibCommunities {
+		c, err := parseCommunity(s)
+		if err != nil {
+			return err
+		}
+		n.ribCommunities = append(n.ribCommunities, c)
+	}
+	for _, s := range opts.RibWatchPrefixes {
+		prefix, _, err := parsePrefix(s)
+		if err != nil {
+			return err
+		}
+		n.ribWatchPrefixes = append(n.ribWatchPrefixes, prefix)
+	}
+	for _, s := range opts.OwnedPrefixes {
+		p, err := parseOwnedPrefix(s)
+		if err != nil {
+			return err
+		}
+		n.ownedPrefixes = append(n.ownedPrefixes, p)
+	}
+	n.forbiddenAsns = make(map[uint32]bool)
+	for _, s := range opts.ForbiddenAsns {
+		asn, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid forbidden AS number %q", s)
+		}
+		n.forbiddenAsns[uint32(asn)] = true
+	}
+	for _, s := range opts.EvpnLabels {
+		if !containsString(evpnLabels, s) {
+			return fmt.Errorf("unsupported EVPN label %q", s)
+		}
+	}
+	for _, s := range opts.VpnRouteDistinguishers {
+		rd, err := parseRouteDistinguisher(s)
+		if err != nil {
+			return err
+		}
+		n.vpnRouteDistinguishers = append(n.vpnRouteDistinguishers, rd)
+	}
+	for _, s := range opts.VpnRouteTargets {
+		rt, err := parseRouteTarget(s)
+		if err != nil {
+			return err
+		}
+		n.vpnRouteTargets = append(n.vpnRouteTargets, rt)
+	}
+	bogons, err := loadBogons(opts.BogonFile)
+	if err != nil {
+		return fmt.Errorf("failed loading bogons: %s", err)
+	}
+	n.bogons = bogons
+	n.ribTables = opts.RibTables
+	n.ribFamilies = opts.RibFamilies
+	n.ribTopN = opts.RibTopN
+	n.evpnLabels = opts.EvpnLabels
+	return nil
+}
+
+// getRibTargets returns the route tables to walk. The Adj-RIB-In and
+// Adj-RIB-Out tables are walked for each established peer.
+func (n *RouterNode) getRibTargets() ([]*ribTarget, error) {
+	var peers []*gobgpapi.Peer
+	targets := []*ribTarget{}
+	for _, table := range n.ribTables {
+		tableType := ribTableTypes[table]
+		names := []string{""}
+		neighbors := []*gobgpapi.Peer{nil}
+		if tableType == gobgpapi.TableType_ADJ_IN || tableType == gobgpapi.TableType_ADJ_OUT {
+			if peers == nil {
+				var err error
+				if peers, err = n.listPeers(n.ctx); err != nil {
+					return nil, err
+				}
+			}
+			names = names[:0]
+			neighbors = neighbors[:0]
+			for _, p := range peers {
+				if p.GetState().GetSessionState() != gobgpapi.PeerState_ESTABLISHED {
+					continue
+				}
+				names = append(names, p.GetState().GetNeighborAddress())
+				neighbors = append(neighbors, p)
+			}
+		}
+		for i, name := range names {
+			for _, family := range n.ribFamilies {
+				targets = append(targets, &ribTarget{
+					table:     table,
+					tableType: tableType,
+					family:    family,
+					peer:      name,
+					neighbor:  neighbors[i],
+					filtered:  tableType == gobgpapi.TableType_ADJ_IN && containsString(n.ribCollectors, "adj_in"),
+				})
+			}
+		}
+	}
+	return targets, nil
+}
+
+// walkRib streams the destinations of a route table, optionally limited to
+// the given prefixes, to the provided function. The destinations are not
+// buffered, so that walking a full table does not hold it in memory.
+func (n *RouterNode) walkRib(t *ribTarget, prefixes []*gobgpapi.TableLookupPrefix, fn func(d *ribDestination)) error {
+	stream, err := n.client.ListPath(n.ctx, &gobgpapi.ListPathRequest{
+		TableType:      t.tableType,
+		Name:           t.peer,
+		Family:         addressFamilies[t.family],
+		Prefixes:       prefixes,
+		EnableFiltered: t.filtered,
+	})
+	if err != nil {
+		return err
+	}
+	for {
+		r, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		fn(&ribDestination{Destination: r.GetDestination()})
+	}
+	return nil
+}
+
+// GetRibStats walks route tables and collects the metrics of the selected
+// optional route table collectors.
+func (n *RouterNode) GetRibStats(collectors []string) {
+	names := []string{}
+	for _, name := range n.ribCollectors {
+		if containsString(collectors, name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+
+	targets, err := n.getRibTargets()
+	if err != nil {
+		level.Error(n.logger).Log(
+			"msg", "GoBGP query for peers failed",
+			"error", err.Error(),
+		)
+		n.IncrementErrorCounter()
+		n.recordError("GoBGP query for peers failed", err)
+		return
+	}
+
+	for _, t := range targets {
+		analyzers := []ribAnalyzer{}
+		for _, name := range names {
+			if a := ribCollectors[name].newAnalyzer(n, t); a != nil {
+				analyzers = append(analyzers, a)
+			}
+		}
+		if len(analyzers) == 0 {
+			continue
+		}
+
+		err := n.walkRib(t, nil, func(d *ribDestination) {
+			for _, a := range analyzers {
+				a.observe(d)
+			}
+		})
+		if err != nil {
+			level.Error(n.logger).Log(
+				"msg", "failed GoBGP query for route table paths",
+				"table_type", t.table,
+				"address_family", t.family,
+				"peer", t.peer,
+				"error", err.Error(),
+			)
+			n.IncrementErrorCounter()
+			n.recordError("failed GoBGP query for route table paths "+t.String(), err)
+			continue
+		}
+
+		for _, a := range analyzers {
+			n.metrics = append(n.metrics, a.metrics()...)
+		}
+	}
+
+	if containsString(names, "churn") {
+		n.pruneChurnStates(targets)
+	}
+}
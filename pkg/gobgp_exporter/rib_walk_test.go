@@ -0,0 +1,40 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetTopCounts(t *testing.T) {
+	counts := map[string]uint64{"a": 1, "b": 3, "c": 3, "d": 2}
+	less := func(i, j string) bool { return i < j }
+	cases := []struct {
+		topN  int
+		keys  []string
+		other uint64
+	}{
+		{topN: 0, keys: []string{}, other: 9},
+		{topN: 2, keys: []string{"b", "c"}, other: 3},
+		{topN: 10, keys: []string{"b", "c", "d", "a"}, other: 0},
+	}
+	for _, test := range cases {
+		keys, other := getTopCounts(counts, test.topN, less)
+		if !reflect.DeepEqual(keys, test.keys) || other != test.other {
+			t.Errorf("top %d: expected %v and %d other, but got %v and %d", test.topN, test.keys, test.other, keys, other)
+		}
+	}
+}
@@ -38,11 +38,30 @@ import (
 type RouterNode struct {
 	sync.RWMutex
 	client               gobgpapi.GobgpApiClient
+	conn                 *grpc.ClientConn
+	ctx                  context.Context
+	cancel               context.CancelFunc
 	address              string
 	routerID             string
 	localAS              uint32
+	global               *gobgpapi.Global
 	resourceTypes        map[string]bool
 	addressFamilies      map[string]bool
+	ribTables            []string
+	ribFamilies          []string
+	collectors           []string
+	ribCollectors        []string
+	ribTopN              int
+	ribCommunities       []string
+	ribWatchPrefixes     []string
+	ownedPrefixes        []*ownedPrefix
+	forbiddenAsns        map[uint32]bool
+	hijackViolations     []*HijackViolation
+	peers                []*gobgpapi.Peer
+	ribSizes             []*RibSize
+	bogons               *bogonList
+	churnStates          map[string]*churnState
+	evpnLabels           []string
 	result               string
 	timestamp            string
 	pollInterval         int64
@@ -50,8 +69,15 @@ type RouterNode struct {
 	errorsLocker         sync.RWMutex
 	nextCollectionTicker int64
 	metrics              []prometheus.Metric
+	caches               map[string]*metricCache
 	connected            bool
+	recentErrors         []*RecentError
 	logger               log.Logger
+
+	// vpnRouteDistinguishers and vpnRouteTargets are the allowlists of
+	// L3VPN route counts.
+	vpnRouteDistinguishers []string
+	vpnRouteTargets        []string
 }
 
 // NewRouterNode creates an instance of RouterNode.
@@ -67,6 +93,7 @@ func NewRouterNode(addr string, timeout int, tlsConfig *tls.Config, logger log.L
 		address:              addr,
 		logger:               logger,
 	}
+	n.ctx, n.cancel = context.WithCancel(context.Background())
 	n.resourceTypes = make(map[string]bool)
 	n.addressFamilies = make(map[string]bool)
 	n.resourceTypes["LOCAL"] = true
@@ -74,7 +101,11 @@ func NewRouterNode(addr string, timeout int, tlsConfig *tls.Config, logger log.L
 	n.addressFamilies["IPv4"] = true
 	n.addressFamilies["EVPN"] = true
 
-	grpcOpts := []grpc.DialOption{grpc.WithBlock()}
+	grpcOpts := []grpc.DialOption{
+		grpc.WithBlock(),
+		grpc.WithUnaryInterceptor(observeUnaryRPC),
+		grpc.WithStreamInterceptor(observeStreamRPC),
+	}
 	if tlsConfig == nil {
 		grpcOpts = append(grpcOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	} else {
@@ -89,10 +120,23 @@ func NewRouterNode(addr string, timeout int, tlsConfig *tls.Config, logger log.L
 		return n, err
 	}
 
+	n.conn = conn
 	n.client = gobgpapi.NewGobgpApiClient(conn)
 	return n, nil
 }
 
+// Close cancels the in-flight queries to the router and closes the gRPC
+// connection.
+func (n *RouterNode) Close() error {
+	if n.cancel != nil {
+		n.cancel()
+	}
+	if n.conn == nil {
+		return nil
+	}
+	return n.conn.Close()
+}
+
 func validAddress(s string, logger log.Logger) error {
 	if s == "" {
 		return fmt.Errorf("empty address")
@@ -135,6 +179,13 @@ func validAddress(s string, logger log.Logger) error {
 	return nil
 }
 
+// IsReady returns true when the most recent poll of the router succeeded.
+func (n *RouterNode) IsReady() bool {
+	n.RLock()
+	defer n.RUnlock()
+	return n.result == "success"
+}
+
 // IncrementErrorCounter increases the counter of failed queries
 // to a network node.
 func (n *RouterNode) IncrementErrorCounter() {
@@ -145,11 +196,16 @@ func (n *RouterNode) IncrementErrorCounter() {
 
 // Collect implements prometheus.Collector.
 func (n *RouterNode) Collect(ch chan<- prometheus.Metric) {
+	n.collect(ch, n.collectors)
+}
+
+// collect sends the metrics of the selected collectors to a channel.
+func (n *RouterNode) collect(ch chan<- prometheus.Metric, collectors []string) {
 	start := time.Now()
 	level.Debug(n.logger).Log(
 		"msg", "Calling GatherMetrics()",
 	)
-	n.GatherMetrics()
+	n.gatherMetrics(collectors)
 	level.Debug(n.logger).Log(
 		"msg", "Collect() calls RLock()",
 	)
@@ -158,7 +214,11 @@ func (n *RouterNode) Collect(ch chan<- prometheus.Metric) {
 	level.Debug(n.logger).Log(
 		"msg", "Collect() successful RLock()",
 	)
-	if len(n.metrics) == 0 {
+	var metrics []prometheus.Metric
+	if cache, exists := n.caches[getCacheKey(collectors)]; exists {
+		metrics = cache.metrics
+	}
+	if len(metrics) == 0 {
 		level.Debug(n.logger).Log(
 			"msg", "Collect() no metrics found",
 		)
@@ -186,9 +246,9 @@ func (n *RouterNode) Collect(ch chan<- prometheus.Metric) {
 	}
 	level.Debug(n.logger).Log(
 		"msg", "Collect() sends metrics to a shared channel",
-		"metric_count", len(n.metrics),
+		"metric_count", len(metrics),
 	)
-	for _, m := range n.metrics {
+	for _, m := range metrics {
 		ch <- m
 	}
 }
@@ -0,0 +1,233 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	gobgpapi "github.com/osrg/gobgp/v3/api"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// stubClient is a GoBGP API client returning canned responses. The calls
+// fail with err, when set, or with the error of a done context.
+type stubClient struct {
+	gobgpapi.GobgpApiClient
+	global       *gobgpapi.Global
+	peers        []*gobgpapi.Peer
+	destinations []*gobgpapi.Destination
+	err          error
+}
+
+// stubStream is a server stream returning canned responses.
+type stubStream[T any] struct {
+	grpc.ClientStream
+	responses []T
+}
+
+func (s *stubStream[T]) Recv() (T, error) {
+	var r T
+	if len(s.responses) == 0 {
+		return r, io.EOF
+	}
+	r, s.responses = s.responses[0], s.responses[1:]
+	return r, nil
+}
+
+func (c *stubClient) GetBgp(ctx context.Context, in *gobgpapi.GetBgpRequest, opts ...grpc.CallOption) (*gobgpapi.GetBgpResponse, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &gobgpapi.GetBgpResponse{Global: c.global}, nil
+}
+
+func (c *stubClient) ListPeer(ctx context.Context, in *gobgpapi.ListPeerRequest, opts ...grpc.CallOption) (gobgpapi.GobgpApi_ListPeerClient, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s := &stubStream[*gobgpapi.ListPeerResponse]{}
+	for _, p := range c.peers {
+		s.responses = append(s.responses, &gobgpapi.ListPeerResponse{Peer: p})
+	}
+	return s, nil
+}
+
+func (c *stubClient) GetTable(ctx context.Context, in *gobgpapi.GetTableRequest, opts ...grpc.CallOption) (*gobgpapi.GetTableResponse, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &gobgpapi.GetTableResponse{}, nil
+}
+
+func (c *stubClient) ListPath(ctx context.Context, in *gobgpapi.ListPathRequest, opts ...grpc.CallOption) (gobgpapi.GobgpApi_ListPathClient, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s := &stubStream[*gobgpapi.ListPathResponse]{}
+	for _, d := range c.destinations {
+		s.responses = append(s.responses, &gobgpapi.ListPathResponse{Destination: d})
+	}
+	return s, nil
+}
+
+// newTestRouterNode returns a router node querying the stub client.
+func newTestRouterNode(t *testing.T, client *stubClient) *RouterNode {
+	t.Helper()
+	n := &RouterNode{
+		client:    client,
+		address:   "127.0.0.1:50051",
+		result:    "unknown",
+		timestamp: "unknown",
+		logger:    log.NewNopLogger(),
+	}
+	n.ctx, n.cancel = context.WithCancel(context.Background())
+	t.Cleanup(func() { n.Close() })
+	if err := n.configureCollectors(Options{}); err != nil {
+		t.Fatalf("failed configuring collectors: %s", err)
+	}
+	return n
+}
+
+// collectMetrics returns the metrics collected from a router node, keyed by
+// their fully-qualified names.
+func collectMetrics(t *testing.T, n *RouterNode) map[string][]*dto.Metric {
+	t.Helper()
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(n)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("failed gathering metrics: %s", err)
+	}
+	metrics := make(map[string][]*dto.Metric)
+	for _, f := range families {
+		metrics[f.GetName()] = f.GetMetric()
+	}
+	return metrics
+}
+
+// getMetricValues returns the values of the metrics built by an analyzer,
+// keyed by their descriptions and the values of their labels.
+func getMetricValues(t *testing.T, metrics []prometheus.Metric) map[*prometheus.Desc]map[string]float64 {
+	t.Helper()
+	values := make(map[*prometheus.Desc]map[string]float64)
+	for _, m := range metrics {
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err != nil {
+			t.Fatalf("failed writing metric: %s", err)
+		}
+		labels := []string{}
+		for _, l := range pb.GetLabel() {
+			labels = append(labels, l.GetName()+"="+l.GetValue())
+		}
+		if values[m.Desc()] == nil {
+			values[m.Desc()] = make(map[string]float64)
+		}
+		var value float64
+		switch {
+		case pb.Gauge != nil:
+			value = pb.GetGauge().GetValue()
+		case pb.Counter != nil:
+			value = pb.GetCounter().GetValue()
+		case pb.Histogram != nil:
+			value = float64(pb.GetHistogram().GetSampleCount())
+		}
+		values[m.Desc()][strings.Join(labels, ",")] = value
+	}
+	return values
+}
+
+func TestGetRouterInfo(t *testing.T) {
+	// The global settings GoBGP does not return in GetBgpResponse must not
+	// be exported with their zero values. GoBGP v3.12.0 GetBgp fills only
+	// the following fields.
+	n := newTestRouterNode(t, &stubClient{global: &gobgpapi.Global{
+		Asn:              65000,
+		RouterId:         "192.0.2.1",
+		ListenPort:       -1,
+		ListenAddresses:  []string{"127.0.0.1"},
+		UseMultiplePaths: true,
+	}})
+	metrics := collectMetrics(t, n)
+
+	info := metrics["gobgp_router_info"]
+	if len(info) != 1 {
+		t.Fatalf("expected 1 gobgp_router_info metric, but got %d", len(info))
+	}
+	labels := []string{}
+	for _, l := range info[0].GetLabel() {
+		labels = append(labels, l.GetName()+"="+l.GetValue())
+	}
+	want := "asn=65000,id=192.0.2.1,listen_addresses=127.0.0.1,listen_port=-1,use_multiple_paths=true"
+	if got := strings.Join(labels, ","); got != want {
+		t.Errorf("expected gobgp_router_info labels %q, but got %q", want, got)
+	}
+	if m := metrics["gobgp_router_use_multiple_paths"]; len(m) != 1 || m[0].GetGauge().GetValue() != 1 {
+		t.Errorf("expected gobgp_router_use_multiple_paths 1, but got %v", m)
+	}
+	for _, name := range []string{
+		"gobgp_router_address_family",
+		"gobgp_router_graceful_restart",
+		"gobgp_router_confederation",
+	} {
+		if _, exists := metrics[name]; exists {
+			t.Errorf("expected no %s metric, but got one", name)
+		}
+	}
+}
+
+func TestReady(t *testing.T) {
+	client := &stubClient{global: &gobgpapi.Global{Asn: 65000, RouterId: "192.0.2.1"}}
+	n := newTestRouterNode(t, client)
+	e := &Exporter{Node: n}
+	ready := func() int {
+		w := httptest.NewRecorder()
+		e.Ready(w, httptest.NewRequest(http.MethodGet, "/-/ready", nil))
+		return w.Code
+	}
+
+	// The router is reachable, but it was never polled.
+	if code := ready(); code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d before poll, but got %d", http.StatusServiceUnavailable, code)
+	}
+	if n.result != "unknown" {
+		t.Errorf("expected no poll of the router by readiness check, but got %q", n.result)
+	}
+	n.GatherMetrics()
+	if code := ready(); code != http.StatusOK {
+		t.Errorf("expected status %d after successful poll, but got %d", http.StatusOK, code)
+	}
+	client.err = status.Error(codes.Unavailable, "connection refused")
+	n.caches = nil
+	n.GatherMetrics()
+	if code := ready(); code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d after failed poll, but got %d", http.StatusServiceUnavailable, code)
+	}
+}